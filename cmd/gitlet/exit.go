@@ -0,0 +1,120 @@
+// cmd/gitlet/exit.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/chesswithmihir/go-gitlet/gitlet"
+)
+
+// Exit codes, documented in docs/design.md. Error messages always go to
+// stderr, so a script can rely on the code and on stdout alone.
+const (
+	exitFailure  = 1 // anything below does not cover; merge-base --is-ancestor: "no"
+	exitUsage    = 2 // unknown command, wrong operands, an empty message or a bad name
+	exitRepo     = 3 // no repository, one already there, or one needing migrate or recover
+	exitNotFound = 4 // no such branch, tag, commit, file or revision
+	exitRefused  = 5 // the repository's state forbids it (untracked file in the way, ...)
+	exitConflict = 6 // merge stopped with conflicts to resolve
+	exitBusy     = 7 // another process holds the lock or moved a ref; try again
+	exitCorrupt  = 8 // damaged objects or refs
+)
+
+// cliError is a failure the command line itself reports.
+type cliError struct {
+	msg  string
+	code int
+}
+
+func (e *cliError) Error() string { return e.msg }
+
+var (
+	errNoCommand      = &cliError{"Please enter a command.", exitUsage}
+	errUnknownCommand = &cliError{"No command with that name exists.", exitUsage}
+)
+
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{gitlet.ErrNotRepo, exitRepo},
+	{gitlet.ErrRepoExists, exitRepo},
+
+	{gitlet.ErrIncorrectOperands, exitUsage},
+	{gitlet.ErrNoCommitMessage, exitUsage},
+	{gitlet.ErrNoTagMessage, exitUsage},
+	{gitlet.ErrBadTagName, exitUsage},
+	{gitlet.ErrPathOutside, exitUsage},
+	{gitlet.ErrPathInGitlet, exitUsage},
+
+	{gitlet.ErrBadRevision, exitNotFound},
+	{gitlet.ErrNoSuchBranch, exitNotFound},
+	{gitlet.ErrNoSuchTag, exitNotFound},
+	{gitlet.ErrNoCommit, exitNotFound},
+	{gitlet.ErrNoMatchingCommit, exitNotFound},
+	{gitlet.ErrFileNotFound, exitNotFound},
+	{gitlet.ErrFileNotInCommit, exitNotFound},
+
+	{gitlet.ErrNothingToRecover, exitRefused},
+	{gitlet.ErrBranchExists, exitRefused},
+	{gitlet.ErrTagExists, exitRefused},
+	{gitlet.ErrRemoveCurrentBranch, exitRefused},
+	{gitlet.ErrAlreadyOnBranch, exitRefused},
+	{gitlet.ErrNothingToCommit, exitRefused},
+	{gitlet.ErrNoReasonToRemove, exitRefused},
+	{gitlet.ErrUntrackedInTheWay, exitRefused},
+	{gitlet.ErrUncommittedChanges, exitRefused},
+	{gitlet.ErrMergeWithSelf, exitRefused},
+	{gitlet.ErrMergeInProgress, exitRefused},
+	{gitlet.ErrNoMerge, exitRefused},
+	{gitlet.ErrUnresolved, exitRefused},
+
+	{gitlet.ErrMergeConflict, exitConflict},
+}
+
+// exitCode picks the exit status for err.
+func exitCode(err error) int {
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.code
+	}
+	var (
+		unsupported *gitlet.UnsupportedFormatError
+		interrupted *gitlet.InterruptedError
+		ambiguous   *gitlet.AmbiguousIDError
+		locked      *gitlet.LockedError
+		moved       *gitlet.RefMovedError
+		corrupt     *gitlet.CorruptObjectError
+		corruptPack *gitlet.CorruptPackError
+	)
+	switch {
+	case errors.As(err, &unsupported), errors.As(err, &interrupted):
+		return exitRepo
+	case errors.As(err, &ambiguous):
+		return exitNotFound
+	case errors.As(err, &locked), errors.As(err, &moved):
+		return exitBusy
+	case errors.As(err, &corrupt), errors.As(err, &corruptPack):
+		return exitCorrupt
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return exitFailure
+}
+
+// fail reports err on stderr and exits with its code.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err.Error())
+	os.Exit(exitCode(err))
+}
+
+// notice writes a message meant for people, not scripts, to stderr.
+func notice(s string) {
+	fmt.Fprintln(os.Stderr, s)
+}
@@ -5,116 +5,312 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/chesswithmihir/go-gitlet/gitlet"
 )
 
+// run opens the repository in the current directory and calls fn on it,
+// failing with the error either returns.
+func run(fn func(r *gitlet.Repository) error) {
+	r, err := gitlet.Open(".")
+	if err == nil {
+		err = fn(r)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
 func main() {
 	args := os.Args[1:]
 	if len(args) == 0 {
-		fmt.Println("Please enter a command.")
-		return
+		fail(errNoCommand)
 	}
 	switch args[0] {
 	case "init":
 		if len(args) != 1 {
-			fmt.Println("Incorrect operands.")
-			return
+			fail(gitlet.ErrIncorrectOperands)
 		}
-		if err := Init("."); err != nil {
-			fmt.Println(err.Error())
+		if _, err := gitlet.Init("."); err != nil {
+			fail(err)
 		}
 
 	case "clear":
 		if len(args) != 1 {
-			fmt.Println("Incorrect operands.")
-			return
+			fail(gitlet.ErrIncorrectOperands)
 		}
-		if err := Clear("."); err != nil {
-			fmt.Println(err.Error())
+		if err := gitlet.Clear("."); err != nil {
+			fail(err)
 		}
 
 	case "add":
 		if len(args) != 2 {
-			fmt.Println("Incorrect operands.")
-			return
-		}
-		if err := Add(".", args[1]); err != nil {
-			fmt.Println(err.Error())
+			fail(gitlet.ErrIncorrectOperands)
 		}
-	
+		run(func(r *gitlet.Repository) error { return r.Add(args[1]) })
+
 	case "commit":
 		if len(args) != 2 {
-			fmt.Println("Incorrect operands.")
-			return
-		}
-		if err := CommitCmd(".", args[1]); err != nil {
-			fmt.Println(err.Error())
+			fail(gitlet.ErrIncorrectOperands)
 		}
+		run(func(r *gitlet.Repository) error {
+			_, err := r.Commit(args[1])
+			return err
+		})
 
 	case "log":
-		if len(args) != 1 {
-			fmt.Println("Incorrect operands.")
-			return
-		}
-		if err := LogCmd("."); err != nil {
-			fmt.Println(err.Error())
+		// log [--date=local] [--oneline] [<revision-or-range>]
+		var f logFormat
+		rev := ""
+		for _, a := range args[1:] {
+			switch {
+			case a == "--date=local":
+				f.LocalDate = true
+			case a == "--oneline":
+				f.Oneline = true
+			case rev == "" && !strings.HasPrefix(a, "-"):
+				rev = a
+			default:
+				fail(gitlet.ErrIncorrectOperands)
+			}
 		}
+		run(func(r *gitlet.Repository) error { return printLog(r, r.Log(rev), f) })
 
 	case "checkout":
 		// checkout -- <file>
 		if len(args) == 3 && args[1] == "--" {
-			if err := CheckoutHeadFile(".", args[2]); err != nil { fmt.Println(err.Error()) }
+			run(func(r *gitlet.Repository) error { return r.CheckoutFile("", args[2]) })
 			return
 		}
 		// checkout <commit> -- <file>
 		if len(args) == 4 && args[2] == "--" {
-			if err := CheckoutCommitFile(".", args[1], args[3]); err != nil { fmt.Println(err.Error()) }
+			run(func(r *gitlet.Repository) error { return r.CheckoutFile(args[1], args[3]) })
 			return
 		}
-		// checkout <branch>
+		// checkout <branch> | checkout <commit-or-tag> (detached HEAD)
 		if len(args) == 2 {
-			if err := CheckoutBranchCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+			run(func(r *gitlet.Repository) error {
+				res, err := r.Checkout(args[1])
+				if err != nil { return err }
+				return warnOrphaned(r, res)
+			})
 			return
 		}
-		fmt.Println("Incorrect operands.")
+		fail(gitlet.ErrIncorrectOperands)
+
 
-	
 	case "status":
 		if len(args) != 1 {
-			fmt.Println("Incorrect operands.")
-			return
+			fail(gitlet.ErrIncorrectOperands)
 		}
-		if err := StatusCmd("."); err != nil { fmt.Println(err.Error()) }
+		run(func(r *gitlet.Repository) error {
+			st, err := r.Status()
+			if err != nil { return err }
+			printStatus(st)
+			return nil
+		})
 
 	case "global-log":
-		if len(args) != 1 { fmt.Println("Incorrect operands."); return }
-		if err := GlobalLogCmd("."); err != nil { fmt.Println(err.Error()) }
+		// global-log [--date=local] [--oneline]
+		var f logFormat
+		for _, a := range args[1:] {
+			switch a {
+			case "--date=local": f.LocalDate = true
+			case "--oneline": f.Oneline = true
+			default: fail(gitlet.ErrIncorrectOperands)
+			}
+		}
+		run(func(r *gitlet.Repository) error { return printLog(r, r.AllCommits(), f) })
 
 	case "find":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := FindCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			ids, err := r.Find(args[1])
+			if err != nil { return err }
+			printLines(ids)
+			return nil
+		})
 
 	case "rm":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := RmCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error { return r.Rm(args[1]) })
 
 	case "branch":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := BranchCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
-	
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error { return r.CreateBranch(args[1]) })
+
 	case "rm-branch":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := RmBranchCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error { return r.DeleteBranch(args[1]) })
 
 	case "reset":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := ResetCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			res, err := r.Reset(args[1])
+			if err != nil { return err }
+			return warnOrphaned(r, res)
+		})
 
 	case "merge":
-		if len(args) != 2 { fmt.Println("Incorrect operands."); return }
-		if err := MergeCmd(".", args[1]); err != nil { fmt.Println(err.Error()) }
+		if len(args) != 2 { fail(gitlet.ErrIncorrectOperands) }
+		switch args[1] {
+		case "--continue":
+			run(func(r *gitlet.Repository) error {
+				_, err := r.MergeContinue()
+				return err
+			})
+		case "--abort":
+			run(func(r *gitlet.Repository) error { return r.MergeAbort() })
+		default:
+			run(func(r *gitlet.Repository) error {
+				res, err := r.Merge(args[1])
+				if err != nil { return err }
+				printMerge(res)
+				return nil
+			})
+		}
+
+	case "diff":
+		// diff | diff --staged | diff <commit> <commit>
+		if len(args) > 3 || (len(args) == 2 && args[1] != "--staged" && args[1] != "--cached") {
+			fail(gitlet.ErrIncorrectOperands)
+		}
+		run(func(r *gitlet.Repository) error {
+			var diffs []gitlet.FileDiff
+			var err error
+			switch len(args) {
+			case 1: diffs, err = r.Diff()
+			case 2: diffs, err = r.DiffStaged()
+			default: diffs, err = r.DiffCommits(args[1], args[2])
+			}
+			if err != nil { return err }
+			for _, d := range diffs { fmt.Print(d.Patch) }
+			return nil
+		})
+
+	case "merge-base":
+		// merge-base [--all] <a> <b> | merge-base --is-ancestor <a> <b>
+		switch {
+		case len(args) == 4 && args[1] == "--is-ancestor":
+			r, err := gitlet.Open(".")
+			if err != nil { fail(err) }
+			ok, err := r.IsAncestor(args[2], args[3])
+			if err != nil { fail(err) }
+			if !ok { os.Exit(exitFailure) }
+		case len(args) == 4 && args[1] == "--all":
+			run(func(r *gitlet.Repository) error {
+				ids, err := r.MergeBases(args[2], args[3], true)
+				printLines(ids)
+				return err
+			})
+		case len(args) == 3:
+			run(func(r *gitlet.Repository) error {
+				ids, err := r.MergeBases(args[1], args[2], false)
+				printLines(ids)
+				return err
+			})
+		default:
+			fail(gitlet.ErrIncorrectOperands)
+		}
+
+	case "tag":
+		// tag | tag <name> [<commit>] | tag -a <name> -m <msg> [<commit>] | tag -d <name>
+		switch {
+		case len(args) == 1:
+			run(func(r *gitlet.Repository) error {
+				names, err := r.Tags()
+				printLines(names)
+				return err
+			})
+		case len(args) == 3 && args[1] == "-d":
+			run(func(r *gitlet.Repository) error { return r.DeleteTag(args[2]) })
+		case (len(args) == 5 || len(args) == 6) && args[1] == "-a" && args[3] == "-m":
+			target := ""
+			if len(args) == 6 { target = args[5] }
+			run(func(r *gitlet.Repository) error { return r.CreateTag(args[2], target, args[4]) })
+		case (len(args) == 2 || len(args) == 3) && !strings.HasPrefix(args[1], "-"):
+			target := ""
+			if len(args) == 3 { target = args[2] }
+			run(func(r *gitlet.Repository) error { return r.CreateTag(args[1], target, "") })
+		default:
+			fail(gitlet.ErrIncorrectOperands)
+		}
+
+	case "rev-parse":
+		if len(args) < 2 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error { return printRevParse(r, args[1:]) })
+
+	case "reflog":
+		// reflog [<branch>|HEAD]
+		if len(args) > 2 { fail(gitlet.ErrIncorrectOperands) }
+		name := ""
+		if len(args) == 2 { name = args[1] }
+		run(func(r *gitlet.Repository) error { return printReflog(r, name) })
+
+	case "gc":
+		// gc [--dry-run] [--grace=<duration>]
+		dryRun, grace := false, ""
+		for _, a := range args[1:] {
+			switch {
+			case a == "--dry-run":
+				dryRun = true
+			case strings.HasPrefix(a, "--grace="):
+				grace = strings.TrimPrefix(a, "--grace=")
+			default:
+				fail(gitlet.ErrIncorrectOperands)
+			}
+		}
+		run(func(r *gitlet.Repository) error {
+			res, err := r.GC(dryRun, grace)
+			if err != nil { return err }
+			printGC(res, dryRun)
+			return nil
+		})
+
+	case "fsck":
+		// fsck [--porcelain]
+		if len(args) > 2 || (len(args) == 2 && args[1] != "--porcelain") { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			problems, err := r.Fsck()
+			if err != nil { return err }
+			return printFsck(problems, len(args) == 2)
+		})
+
+	case "repack":
+		if len(args) != 1 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			n, err := r.Repack()
+			if err != nil { return err }
+			notice(fmt.Sprintf("Packed %d objects.", n))
+			return nil
+		})
+
+	case "migrate":
+		if len(args) != 1 { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			res, err := r.Migrate()
+			if err != nil { return err }
+			printMigrate(res)
+			return nil
+		})
 
+	case "recover":
+		// recover [--continue]
+		if len(args) > 2 || (len(args) == 2 && args[1] != "--continue") { fail(gitlet.ErrIncorrectOperands) }
+		run(func(r *gitlet.Repository) error {
+			forward := len(args) == 2
+			op, err := r.Recover(forward)
+			if err != nil { return err }
+			if forward {
+				notice(fmt.Sprintf("Completed %s.", op))
+			} else {
+				notice(fmt.Sprintf("Rolled back %s.", op))
+			}
+			return nil
+		})
 
 	default:
-		fmt.Println("No command with that name exists.")
+		fail(errUnknownCommand)
 	}
 }
@@ -0,0 +1,211 @@
+// cmd/gitlet/output.go
+
+package main
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/chesswithmihir/go-gitlet/gitlet"
+)
+
+// logFormat holds the display options shared by log and global-log.
+type logFormat struct {
+	LocalDate bool // show dates in the viewer's zone instead of the committer's
+	Oneline   bool // "<abbrev> <subject>" per commit
+
+	abbrev func(string) string // set by prepare when Oneline
+}
+
+func (f *logFormat) prepare(r *gitlet.Repository) error {
+	if !f.Oneline {
+		return nil
+	}
+	ab, err := r.Abbreviate()
+	if err != nil {
+		return err
+	}
+	f.abbrev = ab
+	return nil
+}
+
+// printLog prints every entry of a log, stopping at the first error.
+//
+// spec format example:
+// ===
+// commit <40-hex>
+// Merge: 4975af1 2c1ead1         // only for merge commits
+// Author: Ada Lovelace <ada@example.com>  // absent on the initial commit
+// Commit: Charles Babbage <cb@example.com> // only if committer differs
+// Date: Thu Nov 9 20:00:05 2017 -0800    // committer's offset; viewer's with --date=local
+// <message>
+//
+// With --oneline each commit is one line: "<shortest unique id> <subject>".
+func printLog(r *gitlet.Repository, entries iter.Seq2[gitlet.LogEntry, error], f logFormat) error {
+	if err := f.prepare(r); err != nil {
+		return err
+	}
+	for e, err := range entries {
+		if err != nil {
+			return err
+		}
+		printCommitEntry(e, f)
+	}
+	return nil
+}
+
+// printCommitEntry prints one log entry in the format f selects.
+func printCommitEntry(e gitlet.LogEntry, f logFormat) {
+	c := e.Commit
+	if f.Oneline {
+		fmt.Printf("%s %s\n", f.abbrev(e.ID), c.Subject())
+		return
+	}
+	fmt.Println("===")
+	fmt.Printf("commit %s\n", e.ID)
+	if c.SecondParent != "" && len(c.Parent) >= 7 && len(c.SecondParent) >= 7 {
+		fmt.Printf("Merge: %s %s\n", c.Parent[:7], c.SecondParent[:7])
+	}
+	if c.Author != "" {
+		fmt.Printf("Author: %s\n", c.Author)
+	}
+	if c.Committer != "" && c.Committer != c.Author {
+		fmt.Printf("Commit: %s\n", c.Committer)
+	}
+	fmt.Printf("Date: %s\n", c.Date(f.LocalDate))
+	fmt.Println(c.Message)
+	fmt.Println()
+}
+
+func printStatus(st *gitlet.Status) {
+	fmt.Println("=== Branches ===")
+	if st.Detached != "" {
+		fmt.Printf("*(HEAD detached at %s)\n", st.Detached[:7])
+	}
+	for _, b := range st.Branches {
+		if b == st.Current { fmt.Printf("*%s\n", b) } else { fmt.Println(b) }
+	}
+	fmt.Println()
+
+	printSection := func(title string, lines []string) {
+		fmt.Printf("=== %s ===\n", title)
+		for _, l := range lines { fmt.Println(l) }
+		fmt.Println()
+	}
+	printSection("Staged Files", st.Staged)
+	printSection("Removed Files", st.Removed)
+	printSection("Modifications Not Staged For Commit", st.Modified)
+	printSection("Untracked Files", st.Untracked)
+}
+
+// warnOrphaned tells the user which commits a checkout or reset away from
+// a detached HEAD left unreachable, so they can still be rescued.
+func warnOrphaned(r *gitlet.Repository, res *gitlet.CheckoutResult) error {
+	lost := res.Orphaned
+	if len(lost) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Warning: you are leaving %d commit(s) behind, not connected to any of your branches or tags:", len(lost))}
+	for i, id := range lost {
+		if i == 5 {
+			lines = append(lines, fmt.Sprintf(" ... and %d more.", len(lost)-i))
+			break
+		}
+		e, err := r.ReadCommit(id)
+		if err != nil { return err }
+		lines = append(lines, fmt.Sprintf("  %s %s", id[:7], e.Subject()))
+	}
+	lines = append(lines, fmt.Sprintf("If you want to keep them, check out %s and create a branch there.", lost[0][:7]))
+	notice(strings.Join(lines, "\n"))
+	return nil
+}
+
+// printMerge reports a merge that needed no merge commit; a conflicted one
+// comes back as gitlet.ErrMergeConflict instead.
+func printMerge(res *gitlet.MergeResult) {
+	switch res.Outcome {
+	case gitlet.MergeUpToDate:
+		notice("Given branch is an ancestor of the current branch.")
+	case gitlet.MergeFastForward:
+		notice("Current branch fast-forwarded.")
+	}
+}
+
+// printFsck prints every problem, one per line; porcelain prints them as
+// tab-separated "code kind id detail" fields instead. Only dangling objects
+// is still a clean result.
+func printFsck(problems []gitlet.FsckProblem, porcelain bool) error {
+	bad := 0
+	for _, p := range problems {
+		if porcelain {
+			fmt.Printf("%s\t%s\t%s\t%s\n", p.Code, p.Kind, p.ID, p.Detail)
+		} else {
+			fmt.Println(p.String())
+		}
+		if p.Code != "dangling" {
+			bad++
+		}
+	}
+	if bad > 0 {
+		return &cliError{fmt.Sprintf("Found %d problems.", bad), exitCorrupt}
+	}
+	return nil
+}
+
+func printGC(res *gitlet.GCResult, dryRun bool) {
+	if dryRun {
+		for _, o := range res.Objects {
+			packed := ""
+			if o.Packed { packed = ", packed" }
+			fmt.Printf("Would remove %s %s (%d bytes%s)\n", o.Kind, o.ID, o.Size, packed)
+		}
+		notice(fmt.Sprintf("Would remove %d objects, freeing %d bytes.", len(res.Objects), res.Bytes))
+		return
+	}
+	notice(fmt.Sprintf("Removed %d objects, freeing %d bytes.", len(res.Objects), res.Bytes))
+}
+
+func printMigrate(res *gitlet.MigrateResult) {
+	if res.Backup == "" {
+		notice(fmt.Sprintf("Repository is already at format version %d.", res.To))
+		return
+	}
+	notice(fmt.Sprintf("Backed up .gitlet to %s.", res.Backup))
+	notice(fmt.Sprintf("Repository upgraded to format version %d.", res.To))
+}
+
+// printReflog prints a ref's log, newest first, as "<id> <name>@{n}: <reason>".
+func printReflog(r *gitlet.Repository, name string) error {
+	if name == "" { name = "HEAD" }
+	es, err := r.Reflog(name)
+	if err != nil { return err }
+	ab, err := r.Abbreviate()
+	if err != nil { return err }
+	for n, e := range es {
+		fmt.Printf("%s %s@{%d}: %s\n", ab(e.New), name, n, e.Reason)
+	}
+	return nil
+}
+
+// printRevParse prints the commit id for each revision; ranges print their
+// included ids followed by ^-prefixed excluded ids.
+func printRevParse(r *gitlet.Repository, specs []string) error {
+	for _, spec := range specs {
+		include, exclude, err := r.RevParse(spec)
+		if err != nil { return err }
+		for _, id := range include {
+			fmt.Println(id)
+		}
+		for _, id := range exclude {
+			fmt.Println("^" + id)
+		}
+	}
+	return nil
+}
+
+func printLines(lines []string) {
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+}
@@ -1,28 +1,38 @@
-package main
+package gitlet
 
 import (
-	"errors"
 	"os"
 	"path/filepath"
 )
 
-func Add(cwd, filename string) error {
+func addCmd(rp *repo, filename string) error {
 	// Must be in a repo
-	root, err := gitRoot(cwd)
+	root, err := rp.open()
 	if err != nil {
-		return errNotRepo
+		return err
+	}
+
+	unlock, err := lockRepo(root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filename, err = normalizePath(filename)
+	if err != nil {
+		return err
 	}
 
 	// File must exist
-	abs := filepath.Join(cwd, filename)
+	abs := filepath.Join(rp.dir, filepath.FromSlash(filename))
 	data, err := os.ReadFile(abs)
 	if err != nil {
-		return errors.New("File does not exist.")
+		return ErrFileNotFound
 	}
 
 	// Compute blob id + store
 	bid := blobID(data)
-	if err := ensureBlobStored(root, bid, data); err != nil {
+	if err := ensureBlobStored(rp, bid, data); err != nil {
 		return err
 	}
 
@@ -31,7 +41,7 @@ func Add(cwd, filename string) error {
 	if err != nil {
 		return err
 	}
-	head, err := readCommit(root, headID)
+	head, err := readCommit(rp, headID)
 	if err != nil {
 		return err
 	}
@@ -53,5 +63,8 @@ func Add(cwd, filename string) error {
 		delete(idx.Removes, filename)
 	}
 
-	return idx.save(root)
+	if err := idx.save(root); err != nil {
+		return err
+	}
+	return markResolved(root, filename)
 }
@@ -0,0 +1,29 @@
+package gitlet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+)
+
+// blobID = SHA1("blob\n" + file bytes)
+func blobID(data []byte) string {
+	h := sha1.New()
+	h.Write([]byte("blob\n"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ensureBlobStored writes the blob object if it doesn't already exist.
+func ensureBlobStored(rp *repo, id string, data []byte) error {
+	return ensureObjectStored(rp, "blobs", id, data)
+}
+
+func readBlob(rp *repo, id string) ([]byte, error) {
+	return readObject(rp, "blobs", id)
+}
+
+// readWorktreeBlob reads a blob that is about to be written to the working
+// tree, rehashing it unless core.verifyObjects is never.
+func readWorktreeBlob(rp *repo, id string) ([]byte, error) {
+	return readObjectVerified(rp, "blobs", id, rp.verifyReads(true))
+}
@@ -0,0 +1,45 @@
+package gitlet
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func branchCmd(rp *repo, name string) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+
+	refPath := filepath.Join(root, "refs", "heads", name)
+	if _, err := os.Stat(refPath); err == nil {
+		return ErrBranchExists
+	}
+
+	headID, err := headCommitID(root)
+	if err != nil { return err }
+
+	return updateRef(root, "refs/heads/"+name, zeroID, headID, "branch: Created from HEAD")
+}
+
+func rmBranchCmd(rp *repo, name string) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+
+	refPath := filepath.Join(root, "refs", "heads", name)
+	if _, err := os.Stat(refPath); err != nil {
+		return ErrNoSuchBranch
+	}
+
+	curr, err := currentBranch(root) // "" when detached: any branch may go
+	if err != nil { return err }
+	if name == curr {
+		return ErrRemoveCurrentBranch
+	}
+
+	return deleteRef(root, "refs/heads/"+name)
+}
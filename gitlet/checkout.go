@@ -0,0 +1,72 @@
+package gitlet
+
+// checkout -- <file>
+func checkoutHeadFile(rp *repo, filename string) error {
+	root, err := rp.open()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockRepo(root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	filename, err = normalizePath(filename)
+	if err != nil {
+		return err
+	}
+	headID, err := headCommitID(root)
+	if err != nil {
+		return err
+	}
+	c, err := readCommit(rp, headID)
+	if err != nil {
+		return err
+	}
+	bid, ok := c.Files[filename]
+	if !ok || bid == "" {
+		return ErrFileNotInCommit
+	}
+	data, err := readWorktreeBlob(rp, bid)
+	if err != nil {
+		return err
+	}
+	return writeWorkingFile(rp.dir, filename, data)
+}
+
+// checkout <commit> -- <file>, where <commit> is any revision expression
+func checkoutCommitFile(rp *repo, commitPrefix, filename string) error {
+	root, err := rp.open()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockRepo(root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	filename, err = normalizePath(filename)
+	if err != nil {
+		return err
+	}
+	cid, err := resolveRevision(rp, commitPrefix)
+	if err != nil {
+		return err // prints "No commit with that id exists."
+	}
+	c, err := readCommit(rp, cid)
+	if err != nil {
+		return err
+	}
+	bid, ok := c.Files[filename]
+	if !ok || bid == "" {
+		return ErrFileNotInCommit
+	}
+	data, err := readWorktreeBlob(rp, bid)
+	if err != nil {
+		return err
+	}
+	return writeWorkingFile(rp.dir, filename, data)
+}
+
@@ -0,0 +1,205 @@
+package gitlet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CheckoutResult describes where a checkout or reset left HEAD.
+type CheckoutResult struct {
+	Commit string // commit now checked out
+	Branch string // "" when HEAD is detached
+	// Orphaned lists, newest first, the commits of a previously detached
+	// HEAD that no branch or tag reaches any more.
+	Orphaned []string
+}
+
+// checkoutCmd handles `checkout <name>`: a branch name switches branches;
+// any other revision (id, tag, HEAD~2, ...) detaches HEAD at that commit.
+func checkoutCmd(rp *repo, name string) (*CheckoutResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	if _, err := readBranchID(root, name); err == nil {
+		return checkoutBranchCmd(rp, name)
+	}
+	_, err = resolveRevision(rp, name)
+	if err == nil {
+		return checkoutDetachCmd(rp, name)
+	}
+	var amb *AmbiguousIDError
+	if errors.As(err, &amb) {
+		return nil, err
+	}
+	return nil, restate(ErrNoSuchBranch, "No such branch exists.")
+}
+
+// checkoutBranchCmd switches to <branch> per spec.
+func checkoutBranchCmd(rp *repo, branch string) (*CheckoutResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+
+	// Branch must exist.
+	targetRef := filepath.Join(root, "refs", "heads", branch)
+	if _, err := os.Stat(targetRef); err != nil {
+		return nil, restate(ErrNoSuchBranch, "No such branch exists.")
+	}
+
+	// Must not already be current branch.
+	currBranch, err := currentBranch(root)
+	if err != nil { return nil, err }
+	if currBranch == branch {
+		return nil, ErrAlreadyOnBranch
+	}
+
+	// Load commits.
+	targetIDBytes, _ := os.ReadFile(targetRef)
+	targetID := string(bytesTrimNL(targetIDBytes))
+
+	// Point HEAD to the branch once the tree is switched.
+	j, err := beginJournal(rp, "checkout: moving from "+headName(root, currBranch)+" to "+branch)
+	if err != nil { return nil, err }
+	j.Head[afterSide] = "ref: refs/heads/" + branch
+	res := &CheckoutResult{Commit: targetID, Branch: branch}
+	if res.Orphaned, err = switchTo(rp, targetID, j); err != nil { return nil, err }
+	return res, nil
+}
+
+// checkoutDetachCmd checks out an arbitrary commit (id or tag) with HEAD
+// detached at it; later commits move HEAD itself rather than a branch.
+func checkoutDetachCmd(rp *repo, rev string) (*CheckoutResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+
+	targetID, err := resolveRevision(rp, rev)
+	if err != nil { return nil, err }
+	currBranch, err := currentBranch(root)
+	if err != nil { return nil, err }
+	j, err := beginJournal(rp, "checkout: moving from "+headName(root, currBranch)+" to "+rev)
+	if err != nil { return nil, err }
+	j.Head[afterSide] = targetID
+	res := &CheckoutResult{Commit: targetID}
+	if res.Orphaned, err = switchTo(rp, targetID, j); err != nil { return nil, err }
+	return res, nil
+}
+
+// switchTo replaces the working tree and index with commit targetID, the
+// common part of checkout and detached checkout, and runs j; where HEAD ends
+// up is planned in j by the caller. Leaving a detached HEAD whose commits no
+// branch or tag reaches returns those commits.
+func switchTo(rp *repo, targetID string, j *journal) ([]string, error) {
+
+	// Switching mid-merge would strand the conflict state.
+	if m, err := loadMergeState(rp.root); err != nil {
+		return nil, err
+	} else if m != nil {
+		return nil, ErrMergeInProgress
+	}
+
+	target, err := readCommit(rp, targetID)
+	if err != nil { return nil, err }
+
+	currBranch, currID, err := readHead(rp.root)
+	if err != nil { return nil, err }
+	curr, err := readCommit(rp, currID)
+	if err != nil { return nil, err }
+
+	if err := planSwitch(rp, j, curr, target); err != nil { return nil, err }
+	if err := j.run(rp); err != nil { return nil, err }
+
+	if currBranch == "" && currID != targetID {
+		return orphanedCommits(rp, currID)
+	}
+	return nil, nil
+}
+
+// planSwitch plans in j the rewrite of the working tree from curr's snapshot
+// to target's and the clearing of the index, refusing up front if an
+// untracked file would be overwritten. Shared by checkout and reset.
+func planSwitch(rp *repo, j *journal, curr, target *Commit) error {
+	// Load index to detect "untracked" (not tracked in curr and not staged for add).
+	idx, _ := loadIndex(rp.root)
+
+	// Pre-check: untracked file that would be overwritten by checkout.
+	for fname, bid := range target.Files {
+		abs := filepath.Join(rp.dir, filepath.FromSlash(fname))
+		if _, err := os.Stat(abs); err == nil {
+			_, trackedNow := curr.Files[fname]
+			_, stagedAdd := idx.Adds[fname]
+			if !trackedNow && !stagedAdd {
+				// Optional: compare contents to see if truly overwritten
+				if data, err := os.ReadFile(abs); err == nil {
+					if blobID(data) != bid {
+						return ErrUntrackedInTheWay
+					}
+				} else {
+					// Can't read; be conservative.
+					return ErrUntrackedInTheWay
+				}
+			}
+		}
+	}
+
+	// Files tracked in current but not in target go away.
+	for fname := range curr.Files {
+		if _, ok := target.Files[fname]; !ok {
+			if err := j.touch(rp, fname, ""); err != nil { return err }
+		}
+	}
+	for fname, bid := range target.Files {
+		if err := j.touch(rp, fname, bid); err != nil { return err }
+	}
+
+	// Clear staging area.
+	return j.setIndex(rp, newIndex())
+}
+
+// tiny helper: trim trailing newline from ref files
+func bytesTrimNL(b []byte) []byte {
+	if n := len(b); n > 0 && (b[n-1] == '\n' || b[n-1] == '\r') {
+		return b[:n-1]
+	}
+	return b
+}
+
+// orphanedCommits returns, newest first, the commits reachable from the old
+// detached HEAD oldID that no branch or tag reaches.
+func orphanedCommits(rp *repo, oldID string) ([]string, error) {
+	tips, err := refTips(rp)
+	if err != nil { return nil, err }
+	kept, err := ancestorsOf(rp, tips)
+	if err != nil { return nil, err }
+	if kept[oldID] { return nil, nil }
+
+	// walk back from the old HEAD until reaching kept history
+	var lost []string
+	seen := map[string]bool{oldID: true}
+	queue := []string{oldID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		lost = append(lost, id)
+		c, err := readCommitHeader(rp, id)
+		if err != nil { return nil, err }
+		for _, p := range commitParents(c) {
+			if !seen[p] && !kept[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return lost, nil
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
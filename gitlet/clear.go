@@ -1,6 +1,6 @@
-// cmd/gitlet/clear.go
+// gitlet/clear.go
 
-package main
+package gitlet
 
 import (
 	"fmt"
@@ -15,11 +15,11 @@ func Clear(cwd string) error {
 	gitletDir := filepath.Join(cwd, ".gitlet")
 	// Check if .gitlet directory exists
 	if _, err := os.Stat(gitletDir); os.IsNotExist(err) {
-		return fmt.Errorf("A Gitlet version-control system does not exist in the current directory.")
+		return restate(ErrNotRepo, "A Gitlet version-control system does not exist in the current directory.")
 	}
 	// Remove the .gitlet directory and all its contents
 	if err := os.RemoveAll(gitletDir); err != nil {
-		return fmt.Errorf("Failed to clear the Gitlet repository: %v", err)
+		return fmt.Errorf("Failed to clear the Gitlet repository: %w", err)
 	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+// gitlet/commit.go
+package gitlet
+
+import (
+	"strings"
+	"time"
+)
+
+func commitCmd(rp *repo, msg string) (string, error) {
+	if strings.TrimSpace(msg) == "" {
+		return "", ErrNoCommitMessage
+	}
+	root, err := rp.open()
+	if err != nil {
+		return "", err
+	}
+
+	unlock, err := lockRepo(root)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Load index (staged adds/removes)
+	idx, err := loadIndex(root)
+	if err != nil {
+		return "", err
+	}
+	// An in-progress merge may be committed with nothing staged (the
+	// resolution can equal HEAD), but only once every conflict is resolved.
+	merge, err := loadMergeState(root)
+	if err != nil {
+		return "", err
+	}
+	if merge != nil && len(merge.Conflicts) > 0 {
+		return "", ErrUnresolved
+	}
+	if merge == nil && len(idx.Adds) == 0 && len(idx.Removes) == 0 {
+		return "", ErrNothingToCommit
+	}
+
+	// Parent = current HEAD
+	parentID, err := headCommitID(root)
+	if err != nil {
+		return "", err
+	}
+	parent, err := readCommit(rp, parentID)
+	if err != nil {
+		return "", err
+	}
+
+	// Snapshot = copy of parent, then apply removes and adds
+	newSnap := make(map[string]string, len(parent.Files))
+	for k, v := range parent.Files {
+		newSnap[k] = v
+	}
+	for f := range idx.Removes {
+		delete(newSnap, f)
+	}
+	for f, bid := range idx.Adds {
+		newSnap[f] = bid
+	}
+
+	// New commit
+	c := &Commit{
+		Message:      msg,
+		TimestampRFC: commitTimestamp(time.Now()),
+		Parent:       parentID,
+		SecondParent: "",
+		Files:        newSnap,
+	}
+	if merge != nil {
+		c.SecondParent = merge.Head
+	}
+	stampIdentity(root, c)
+
+	// Store tree + commit objects
+	cid, err := writeCommit(rp, c)
+	if err != nil {
+		return "", err
+	}
+
+	// Move current branch ref (or a detached HEAD) to new commit
+	reason := "commit: "
+	if merge != nil {
+		reason = "commit (merge): "
+	}
+	if err := updateHead(root, parentID, cid, reason+firstLine(msg)); err != nil {
+		return "", err
+	}
+
+	// Clear index and any merge this commit concluded
+	idx.clear()
+	if err := idx.save(root); err != nil {
+		return "", err
+	}
+	return cid, clearMergeState(root)
+}
@@ -0,0 +1,113 @@
+package gitlet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readCommit loads a commit by id from objects/commits/<shard>/<rest>, with
+// its snapshot expanded into c.Files.
+func readCommit(rp *repo, id string) (*Commit, error) {
+	c, err := readCommitHeader(rp, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Tree != "" {
+		files, err := flattenTree(rp, c.Tree)
+		if err != nil { return nil, err }
+		c.Files = files
+	}
+	return c, nil
+}
+
+// readCommitHeader loads a commit without reading its tree, for walks that
+// only need parents, messages and dates (see decodeCommit).
+func readCommitHeader(rp *repo, id string) (*Commit, error) {
+	b, err := readObject(rp, "commits", id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCommit(b)
+}
+
+// decodeCommit parses a stored commit without reading its tree: c.Files is
+// only filled for legacy commits that list their files inline.
+func decodeCommit(b []byte) (*Commit, error) {
+	r := bufio.NewReader(strings.NewReader(string(b)))
+
+	read := func() (string, error) {
+		s, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(s, "\n"), err
+	}
+
+	expect := func(got, want string) error {
+		if got != want {
+			return fmt.Errorf("bad commit: expected %q, got %q", want, got)
+		}
+		return nil
+	}
+
+	// Follows CanonicalBytes(): key\nvalue\n ... (author/committer optional) then either "tree\n<id>\n"
+	// or, for commits written before tree objects, "files\n" then entries.
+	// The message comes first, or last when it has more than one line.
+	c := &Commit{Files: map[string]string{}}
+
+	l, _ := read()
+	inline := l == "message"
+	if inline {
+		c.Message, _ = read()
+		l, _ = read()
+	}
+	if err := expect(l, "timestamp"); err != nil { return nil, err }
+	c.TimestampRFC, _ = read()
+	l, _ = read(); if err := expect(l, "parent"); err != nil { return nil, err }
+	c.Parent, _ = read()
+	l, _ = read(); if err := expect(l, "parent2"); err != nil { return nil, err }
+	c.SecondParent, _ = read()
+	l, _ = read()
+	for l == "author" || l == "committer" {
+		v, _ := read()
+		if l == "author" { c.Author = v } else { c.Committer = v }
+		l, _ = read()
+	}
+	if l == "tree" {
+		c.Tree, _ = read()
+		l, _ = read()
+	} else {
+		if err := expect(l, "files"); err != nil { return nil, err }
+		l = ""
+		for {
+			line, err2 := read()
+			if line == "" && errors.Is(err2, io.EOF) {
+				break
+			}
+			if line == "" && err2 == nil {
+				continue
+			}
+			if line == "message" { // entries always hold a tab
+				l = line
+				break
+			}
+			parts := strings.SplitN(line, "\t", 2)
+			if len(parts) == 2 {
+				c.Files[parts[0]] = parts[1]
+			}
+			if errors.Is(err2, io.EOF) {
+				break
+			}
+		}
+	}
+	if !inline {
+		if err := expect(l, "message"); err != nil { return nil, err }
+		rest, err := io.ReadAll(r)
+		if err != nil { return nil, err }
+		c.Message = strings.TrimSuffix(string(rest), "\n")
+	}
+	return c, nil
+}
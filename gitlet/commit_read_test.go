@@ -0,0 +1,122 @@
+package gitlet
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Walks over history only need commit headers, so they keep working when
+// a commit's tree cannot be read.
+func TestWalksDoNotReadTrees(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "a.txt", "a\n")
+	if err := r.Add("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := r.Commit("add a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := r.ReadCommit(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Files["a.txt"] == "" {
+		t.Fatalf("ReadCommit files = %v, want a.txt", e.Files)
+	}
+	_, path := objectPath(filepath.Join(dir, ".gitlet"), "trees", e.Tree)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	for _, err := range r.Log("") {
+		if err != nil {
+			t.Fatalf("log: %v", err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("log gave %d commits, want 2", n)
+	}
+	if ok, err := r.IsAncestor("HEAD~1", "HEAD"); err != nil || !ok {
+		t.Fatalf("is-ancestor = %v, %v", ok, err)
+	}
+	if found, err := r.Find("add a"); err != nil || len(found) != 1 {
+		t.Fatalf("find = %v, %v", found, err)
+	}
+	if _, err := r.ReadCommit(id); err == nil {
+		t.Fatal("ReadCommit succeeded without the tree")
+	}
+}
+
+func TestMultilineMessageRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []string{"subj\n\nbody", "two\nlines\n", "one line"}
+	for i, msg := range msgs {
+		writeFile(t, dir, "a.txt", msg)
+		if err := r.Add("a.txt"); err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.Commit(msg)
+		if err != nil {
+			t.Fatalf("commit %q: %v", msg, err)
+		}
+		e, err := r.ReadCommit(id)
+		if err != nil {
+			t.Fatalf("commit %d: %v", i, err)
+		}
+		if e.Message != msg {
+			t.Fatalf("message = %q, want %q", e.Message, msg)
+		}
+		if e.Files["a.txt"] == "" || e.Parent == "" {
+			t.Fatalf("commit %q read back as %+v", msg, e.Commit)
+		}
+	}
+	n := 0
+	for e, err := range r.Log("") {
+		if err != nil {
+			t.Fatalf("log: %v", err)
+		}
+		if e.Subject() == "subj" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("log has %d commits with subject subj, want 1", n)
+	}
+}
+
+// Legacy commits list their files inline; a multi-line message still comes
+// after them, and a one-line message keeps its place first.
+func TestDecodeCommitMessagePlacement(t *testing.T) {
+	for _, msg := range []string{"one line", "subj\n\nbody"} {
+		c := &Commit{
+			Message:      msg,
+			TimestampRFC: "1970-01-01T00:00:00Z",
+			Files:        map[string]string{"a.txt": "1234", "message": "5678"},
+		}
+		b := c.CanonicalBytes()
+		if inline := strings.HasPrefix(string(b), "message\n"); inline == strings.Contains(msg, "\n") {
+			t.Errorf("%q: message first = %v", msg, inline)
+		}
+		got, err := decodeCommit(b)
+		if err != nil {
+			t.Fatalf("%q: %v", msg, err)
+		}
+		if got.Message != msg || !maps.Equal(got.Files, c.Files) {
+			t.Fatalf("%q decoded as %q, %v", msg, got.Message, got.Files)
+		}
+	}
+}
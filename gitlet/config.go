@@ -0,0 +1,129 @@
+package gitlet
+
+import (
+	"bufio"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// config maps "section.key" (lowercase) to its value. Files use a small
+// git-style INI syntax:
+//
+//	# comment
+//	[user]
+//		name = Ada Lovelace
+//		email = ada@example.com
+type config map[string]string
+
+// userConfigPath is the per-user settings file, ~/.gitletconfig.
+func userConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gitletconfig")
+}
+
+func repoConfigPath(root string) string { return filepath.Join(root, "config") }
+
+// loadConfig merges the user-level file and .gitlet/config; repository
+// settings win. Missing files are simply empty.
+func loadConfig(root string) config {
+	cfg := config{}
+	for _, p := range []string{userConfigPath(), repoConfigPath(root)} {
+		if p == "" {
+			continue
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		for k, v := range parseConfig(string(b)) {
+			cfg[k] = v
+		}
+	}
+	return cfg
+}
+
+func parseConfig(text string) config {
+	cfg := config{}
+	section := ""
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(k))
+		if section != "" {
+			key = section + "." + key
+		}
+		cfg[key] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+	return cfg
+}
+
+// get returns the value for key, or def when unset.
+func (c config) get(key, def string) string {
+	if v, ok := c[key]; ok {
+		return v
+	}
+	return def
+}
+
+// ---- Identities ----
+
+type identity struct {
+	Name  string
+	Email string
+}
+
+// String renders the "Name <email>" form stored in commits.
+func (id identity) String() string { return id.Name + " <" + id.Email + ">" }
+
+// resolveIdentity picks the author or committer ("AUTHOR" / "COMMITTER")
+// identity: GITLET_<ROLE>_NAME / _EMAIL win over user.name / user.email from
+// the config files, which win over the login name and host.
+func resolveIdentity(cfg config, role string) identity {
+	id := identity{
+		Name:  os.Getenv("GITLET_" + role + "_NAME"),
+		Email: os.Getenv("GITLET_" + role + "_EMAIL"),
+	}
+	if id.Name == "" {
+		id.Name = cfg.get("user.name", "")
+	}
+	if id.Email == "" {
+		id.Email = cfg.get("user.email", "")
+	}
+	if id.Name == "" || id.Email == "" {
+		login := "unknown"
+		if u, err := user.Current(); err == nil && u.Username != "" {
+			login = u.Username
+		}
+		host, _ := os.Hostname()
+		if id.Name == "" {
+			id.Name = login
+		}
+		if id.Email == "" {
+			id.Email = login + "@" + host
+		}
+	}
+	return id
+}
+
+// stampIdentity fills in the author and committer of a commit about to be written.
+func stampIdentity(root string, c *Commit) {
+	cfg := loadConfig(root)
+	c.Author = resolveIdentity(cfg, "AUTHOR").String()
+	c.Committer = resolveIdentity(cfg, "COMMITTER").String()
+}
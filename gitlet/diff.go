@@ -0,0 +1,254 @@
+package gitlet
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const diffContext = 3
+
+// diffSnapshot is one side of a diff: path -> blobID, plus working-tree
+// contents that are not (necessarily) in the object store.
+type diffSnapshot struct {
+	files map[string]string
+	data  map[string][]byte // blobID -> bytes for working-tree files
+}
+
+func (s *diffSnapshot) load(rp *repo, bid string) ([]byte, error) {
+	if d, ok := s.data[bid]; ok {
+		return d, nil
+	}
+	return readBlob(rp, bid)
+}
+
+// FileDiff is one changed path; OldID or NewID is "" when the file was
+// created or deleted. Patch is the unified diff (or "Binary files ... differ").
+type FileDiff struct {
+	Path         string
+	OldID, NewID string
+	Patch        string
+}
+
+// diffCmd implements:
+//
+//	diff                   working tree vs index
+//	diff --staged          index vs HEAD
+//	diff <commit> <commit> between two commits
+func diffCmd(rp *repo, args []string) ([]FileDiff, error) {
+	_, err := rp.open()
+	if err != nil { return nil, err }
+
+	var a, b *diffSnapshot
+	var changed map[string][2]string
+	switch {
+	case len(args) == 0:
+		idx, err := indexSnapshot(rp)
+		if err != nil { return nil, err }
+		work, err := workingSnapshot(rp.dir, idx.files)
+		if err != nil { return nil, err }
+		a, b = idx, work
+	case len(args) == 1 && (args[0] == "--staged" || args[0] == "--cached"):
+		head, err := headSnapshot(rp)
+		if err != nil { return nil, err }
+		idx, err := indexSnapshot(rp)
+		if err != nil { return nil, err }
+		a, b = head, idx
+	case len(args) == 2:
+		ca, err := readResolvedCommit(rp, args[0])
+		if err != nil { return nil, err }
+		cb, err := readResolvedCommit(rp, args[1])
+		if err != nil { return nil, err }
+		// trees let us skip identical subdirectories without reading them
+		changed, err = diffCommits(rp, ca, cb)
+		if err != nil { return nil, err }
+		a, b = &diffSnapshot{files: ca.Files}, &diffSnapshot{files: cb.Files}
+	default:
+		return nil, ErrIncorrectOperands
+	}
+	if changed == nil {
+		changed = diffFileMaps(a.files, b.files)
+	}
+
+	paths := make([]string, 0, len(changed))
+	for p := range changed { paths = append(paths, p) }
+	sort.Strings(paths)
+
+	var diffs []FileDiff
+	for _, p := range paths {
+		ids := changed[p]
+		var oldData, newData []byte
+		if ids[0] != "" {
+			if oldData, err = a.load(rp, ids[0]); err != nil { return nil, err }
+		}
+		if ids[1] != "" {
+			if newData, err = b.load(rp, ids[1]); err != nil { return nil, err }
+		}
+		diffs = append(diffs, FileDiff{Path: p, OldID: ids[0], NewID: ids[1],
+			Patch: unifiedFileDiff(p, oldData, newData, ids[0] == "", ids[1] == "")})
+	}
+	return diffs, nil
+}
+
+func readResolvedCommit(rp *repo, rev string) (*Commit, error) {
+	id, err := resolveRevision(rp, rev)
+	if err != nil { return nil, err }
+	return readCommit(rp, id)
+}
+
+func headSnapshot(rp *repo) (*diffSnapshot, error) {
+	headID, err := headCommitID(rp.root)
+	if err != nil { return nil, err }
+	head, err := readCommit(rp, headID)
+	if err != nil { return nil, err }
+	return &diffSnapshot{files: head.Files}, nil
+}
+
+// indexSnapshot is HEAD with the staged adds and removes applied.
+func indexSnapshot(rp *repo) (*diffSnapshot, error) {
+	head, err := headSnapshot(rp)
+	if err != nil { return nil, err }
+	idx, err := loadIndex(rp.root)
+	if err != nil { return nil, err }
+	files := make(map[string]string, len(head.files))
+	for f, bid := range head.files { files[f] = bid }
+	for f := range idx.Removes { delete(files, f) }
+	for f, bid := range idx.Adds { files[f] = bid }
+	return &diffSnapshot{files: files}, nil
+}
+
+// workingSnapshot hashes the working copies of the given tracked paths;
+// untracked files are not part of a working-tree diff.
+func workingSnapshot(cwd string, tracked map[string]string) (*diffSnapshot, error) {
+	s := &diffSnapshot{files: map[string]string{}, data: map[string][]byte{}}
+	for f := range tracked {
+		data, err := os.ReadFile(filepath.Join(cwd, filepath.FromSlash(f)))
+		if err != nil {
+			continue // deleted in the working tree
+		}
+		bid := blobID(data)
+		s.files[f] = bid
+		s.data[bid] = data
+	}
+	return s, nil
+}
+
+func diffFileMaps(a, b map[string]string) map[string][2]string {
+	out := map[string][2]string{}
+	for f, bid := range a {
+		if b[f] != bid { out[f] = [2]string{bid, b[f]} }
+	}
+	for f, bid := range b {
+		if _, ok := a[f]; !ok { out[f] = [2]string{"", bid} }
+	}
+	return out
+}
+
+// isBinary uses the same heuristic as git: a NUL byte in the first 8000 bytes.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// unifiedFileDiff renders one file's change with a git-style header.
+func unifiedFileDiff(name string, oldData, newData []byte, created, deleted bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", name, name)
+	oldName, newName := "a/"+name, "b/"+name
+	if created {
+		sb.WriteString("new file\n")
+		oldName = "/dev/null"
+	}
+	if deleted {
+		sb.WriteString("deleted file\n")
+		newName = "/dev/null"
+	}
+	if isBinary(oldData) || isBinary(newData) {
+		fmt.Fprintf(&sb, "Binary files %s and %s differ\n", oldName, newName)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	sb.WriteString(unifiedHunks(splitLines(oldData), splitLines(newData), diffContext))
+	return sb.String()
+}
+
+// unifiedHunks formats the edit script from a to b as "@@ -l,s +l,s @@" hunks
+// with ctx lines of context, merging hunks whose context would overlap.
+func unifiedHunks(a, b []string, ctx int) string {
+	edits := diffLines(a, b)
+
+	// Group edits into hunks: runs of changes separated by more than 2*ctx equal lines.
+	type span struct{ start, end int } // [start, end) into edits
+	var hunks []span
+	for i := 0; i < len(edits); i++ {
+		if edits[i].Op == '=' { continue }
+		start := i - ctx
+		if start < 0 { start = 0 }
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			start = hunks[n-1].start
+			hunks = hunks[:n-1]
+		}
+		end := i + 1
+		for end < len(edits) && edits[end].Op != '=' { end++ }
+		i = end - 1
+		end += ctx
+		if end > len(edits) { end = len(edits) }
+		hunks = append(hunks, span{start, end})
+	}
+
+	var sb strings.Builder
+	for _, h := range hunks {
+		aStart, bStart := -1, -1
+		aLen, bLen := 0, 0
+		for _, e := range edits[h.start:h.end] {
+			if e.Op != '+' {
+				if aStart < 0 { aStart = e.A }
+				aLen++
+			}
+			if e.Op != '-' {
+				if bStart < 0 { bStart = e.B }
+				bLen++
+			}
+		}
+		// An empty side is reported at the line before the hunk.
+		first := edits[h.start]
+		if aStart < 0 { aStart = first.A }
+		if bStart < 0 { bStart = first.B }
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, e := range edits[h.start:h.end] {
+			switch e.Op {
+			case '=':
+				writeDiffLine(&sb, ' ', a[e.A])
+			case '-':
+				writeDiffLine(&sb, '-', a[e.A])
+			case '+':
+				writeDiffLine(&sb, '+', b[e.B])
+			}
+		}
+	}
+	return sb.String()
+}
+
+// hunkRange formats a 0-based start and length as unified diff's 1-based "l,s".
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+func writeDiffLine(sb *strings.Builder, op byte, line string) {
+	sb.WriteByte(op)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,65 @@
+package gitlet
+
+import "errors"
+
+// Every failure the spec (or a later command) names has a sentinel here, so
+// callers can tell them apart with errors.Is; the text is the message the
+// command prints. Failures that carry details are the error types
+// UnsupportedFormatError, InterruptedError, LockedError, RefMovedError,
+// CorruptObjectError, CorruptPackError and AmbiguousIDError.
+var (
+	// the repository itself
+	ErrNotRepo          = errors.New("Not in an initialized Gitlet directory.")
+	ErrRepoExists       = errors.New("A Gitlet version-control system already exists in the current directory.")
+	ErrNothingToRecover = errors.New("There is no interrupted command to recover.")
+
+	// bad arguments
+	ErrIncorrectOperands = errors.New("Incorrect operands.")
+	ErrNoCommitMessage   = errors.New("Please enter a commit message.")
+	ErrNoTagMessage      = errors.New("Please enter a tag message.")
+	ErrBadTagName        = errors.New("Invalid tag name.")
+	ErrPathOutside       = errors.New("Path is outside the working tree.")
+	ErrPathInGitlet      = errors.New("Path is inside the .gitlet directory.")
+	ErrBadRevision       = errors.New("Invalid revision.")
+
+	// things that do not exist
+	ErrNoSuchBranch     = errors.New("A branch with that name does not exist.")
+	ErrNoSuchTag        = errors.New("A tag with that name does not exist.")
+	ErrNoCommit         = errors.New("No commit with that id exists.")
+	ErrNoMatchingCommit = errors.New("Found no commit with that message.")
+	ErrFileNotFound     = errors.New("File does not exist.")
+	ErrFileNotInCommit  = errors.New("File does not exist in that commit.")
+
+	// refused because of the repository's state
+	ErrBranchExists        = errors.New("A branch with that name already exists.")
+	ErrTagExists           = errors.New("A tag with that name already exists.")
+	ErrRemoveCurrentBranch = errors.New("Cannot remove the current branch.")
+	ErrAlreadyOnBranch     = errors.New("No need to checkout the current branch.")
+	ErrNothingToCommit     = errors.New("No changes added to the commit.")
+	ErrNoReasonToRemove    = errors.New("No reason to remove the file.")
+	ErrUntrackedInTheWay   = errors.New("There is an untracked file in the way; delete it, or add and commit it first.")
+	ErrUncommittedChanges  = errors.New("You have uncommitted changes.")
+	ErrMergeWithSelf       = errors.New("Cannot merge a branch with itself.")
+	ErrMergeInProgress     = errors.New("A merge is in progress; commit the resolution, or run merge --abort.")
+	ErrNoMerge             = errors.New("There is no merge in progress.")
+	ErrUnresolved          = errors.New("You have unresolved merge conflicts.")
+
+	// Merge returns ErrMergeConflict, along with its result, when it stops
+	// to let conflicts be resolved.
+	ErrMergeConflict = errors.New("Encountered a merge conflict.")
+)
+
+// restatedError is a sentinel failure worded differently, because the spec
+// words it differently for one command: it prints msg but errors.Is matches
+// the sentinel.
+type restatedError struct {
+	msg  string
+	base error
+}
+
+func (e *restatedError) Error() string { return e.msg }
+func (e *restatedError) Unwrap() error { return e.base }
+
+func restate(base error, msg string) error {
+	return &restatedError{msg: msg, base: base}
+}
@@ -0,0 +1,234 @@
+package gitlet
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// .gitlet/format records the on-disk format a repository uses:
+//
+//	version 1
+//	feature compressed-objects
+//	feature packs
+//	...
+//
+// Every feature listed is required: a gitlet that does not know one of them
+// (or a newer version) refuses to touch the repository. Repositories made
+// before the file existed are version 0 and still work; migrate upgrades them.
+const formatVersion = 1
+
+// formatFeatures are the features this gitlet understands, all of which
+// init and migrate record.
+var formatFeatures = []string{
+	"compressed-objects", // zlib loose objects (see encodeObject)
+	"packs",              // objects/pack (see repack)
+	"reflogs",            // logs/ (see updateRef)
+	"tags",               // refs/tags and tag objects
+	"trees",              // commits point at tree objects
+}
+
+type repoFormat struct {
+	Version  int
+	Features []string
+}
+
+// UnsupportedFormatError is returned for repositories written by a newer gitlet.
+type UnsupportedFormatError struct {
+	Version int
+	Unknown []string // required features this gitlet does not know
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	if e.Version > formatVersion {
+		return fmt.Sprintf("This repository uses format version %d; this gitlet supports up to %d.", e.Version, formatVersion)
+	}
+	return fmt.Sprintf("This repository requires unsupported features: %s.", strings.Join(e.Unknown, ", "))
+}
+
+func formatPath(root string) string { return filepath.Join(root, "format") }
+
+func currentFormat() *repoFormat {
+	return &repoFormat{Version: formatVersion, Features: append([]string(nil), formatFeatures...)}
+}
+
+// readFormat returns version 0 with no features when the file is missing.
+func readFormat(root string) (*repoFormat, error) {
+	b, err := os.ReadFile(formatPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return &repoFormat{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := &repoFormat{}
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch k {
+		case "version":
+			if f.Version, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("bad format file: %q", line)
+			}
+		case "feature":
+			f.Features = append(f.Features, v)
+		}
+	}
+	return f, nil
+}
+
+func (f *repoFormat) save(root string) error {
+	sort.Strings(f.Features)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "version %d\n", f.Version)
+	for _, feat := range f.Features {
+		fmt.Fprintf(&sb, "feature %s\n", feat)
+	}
+	return writeAtomic(formatPath(root), []byte(sb.String()))
+}
+
+// checkFormat refuses repositories this gitlet cannot safely read or write.
+func checkFormat(root string) error {
+	f, err := readFormat(root)
+	if err != nil {
+		return err
+	}
+	if f.Version > formatVersion {
+		return &UnsupportedFormatError{Version: f.Version}
+	}
+	var unknown []string
+	for _, feat := range f.Features {
+		if !slices.Contains(formatFeatures, feat) {
+			unknown = append(unknown, feat)
+		}
+	}
+	if len(unknown) > 0 {
+		return &UnsupportedFormatError{Version: f.Version, Unknown: unknown}
+	}
+	return nil
+}
+
+// migrations[v] upgrades a repository from format version v to v+1.
+var migrations = []func(root string) error{
+	migrateFrom0,
+}
+
+// migrateFrom0 brings a pre-format repository up to version 1: directories
+// later features expect, and loose objects stored raw are recompressed.
+// Commits that list their files inline keep doing so, since rewriting them
+// would change their ids.
+func migrateFrom0(root string) error {
+	for _, d := range []string{"refs/tags", "objects/trees", "objects/tags", "logs"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(d)), 0o755); err != nil {
+			return err
+		}
+	}
+	for _, kind := range objectKinds {
+		objs, err := listObjects(root, kind)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			_, path := objectPath(root, kind, o.ID)
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			// the prefix alone cannot tell: a raw blob may start with it too
+			if data, err := decompressObject(kind, raw); err == nil && hashObject(kind, data) == o.ID {
+				continue
+			}
+			if hashObject(kind, raw) != o.ID {
+				continue // damaged either way; left as it is for fsck to report
+			}
+			enc, err := encodeObject(kind, raw)
+			if err != nil {
+				return err
+			}
+			if err := writeAtomic(path, enc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backupRepo copies everything in .gitlet except earlier backups to
+// .gitlet/backups/<timestamp> and returns that directory.
+func backupRepo(root string) (string, error) {
+	dest := filepath.Join(root, "backups", time.Now().UTC().Format("20060102T150405.000000000Z"))
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "backups" {
+			return filepath.SkipDir
+		}
+		if isLockOrTemp(d.Name()) {
+			return nil
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	return dest, err
+}
+
+// MigrateResult reports a migrate: From == To when there was nothing to do.
+type MigrateResult struct {
+	From, To int
+	Backup   string // copy of .gitlet taken before upgrading
+}
+
+// migrateCmd upgrades the repository to the current format in place, after
+// backing up .gitlet.
+func migrateCmd(rp *repo) (*MigrateResult, error) {
+	root, err := rp.find()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+	f, err := readFormat(root)
+	if err != nil { return nil, err }
+	if f.Version > formatVersion {
+		return nil, &UnsupportedFormatError{Version: f.Version}
+	}
+	cur := currentFormat()
+	if f.Version == formatVersion {
+		for _, feat := range f.Features {
+			if !slices.Contains(cur.Features, feat) {
+				return nil, &UnsupportedFormatError{Version: f.Version, Unknown: []string{feat}}
+			}
+		}
+		if len(f.Features) == len(cur.Features) {
+			return &MigrateResult{From: f.Version, To: formatVersion}, nil
+		}
+	}
+
+	dest, err := backupRepo(root)
+	if err != nil { return nil, err }
+
+	for v := f.Version; v < formatVersion; v++ {
+		if err := migrations[v](root); err != nil {
+			return nil, fmt.Errorf("Migration from format version %d failed (backup in %s): %w", v, filepath.ToSlash(dest), err)
+		}
+	}
+	if err := cur.save(root); err != nil { return nil, err }
+	return &MigrateResult{From: f.Version, To: formatVersion, Backup: filepath.ToSlash(dest)}, nil
+}
@@ -0,0 +1,52 @@
+package gitlet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateFrom0RecompressesRawObjects(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Init(dir); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, ".gitlet")
+
+	raws := [][]byte{
+		[]byte("plain legacy blob\n"),
+		[]byte(looseMagic + "blob 5\nlooks compressed but is not"),
+		{},
+		[]byte("x"),
+		[]byte("hi\n"),
+	}
+	for _, raw := range raws {
+		id := hashObject("blobs", raw)
+		sub, path := objectPath(root, "blobs", id)
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, raw, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := migrateFrom0(root); err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range raws {
+		id := hashObject("blobs", raw)
+		_, path := objectPath(root, "blobs", id)
+		stored, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := decompressObject("blobs", stored)
+		if err != nil {
+			t.Fatalf("%s was not recompressed: %v", id, err)
+		}
+		if !bytes.Equal(data, raw) {
+			t.Fatalf("%s decompresses to %q, want %q", id, data, raw)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package gitlet
+
+import (
+	"sort"
+	"strings"
+)
+
+// FsckProblem is one finding. Code is one of hash-mismatch, unreadable,
+// missing, bad-ref or dangling; Kind is the object type (blob, commit, tree,
+// tag) or "ref" for refs and the index.
+type FsckProblem struct {
+	Code, Kind, ID, Detail string
+}
+
+func (p FsckProblem) String() string {
+	s := strings.ReplaceAll(p.Code, "-", " ") + " " + p.Kind + " " + p.ID
+	if p.Detail != "" {
+		s += ": " + p.Detail
+	}
+	return s
+}
+
+// fsckRepo checks every stored object against its id, every link between
+// objects, and every ref, index entry and merge-state entry. Objects nothing
+// points at (not other objects, refs, reflogs, the index or a merge) are
+// reported as dangling.
+func fsckRepo(rp *repo) ([]FsckProblem, error) {
+	var problems []FsckProblem
+	report := func(code, kind, id, detail string) {
+		problems = append(problems, FsckProblem{code, kind, id, detail})
+	}
+
+	store := rp.store
+	have := map[string]string{} // id -> kind directory
+	for _, kind := range objectKinds {
+		err := store.Iterate(kind, func(id string) error {
+			have[id] = kind
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	referenced := map[string]bool{}
+	// link records that from points at id, which must be an object of kind.
+	link := func(from, kind, id string) {
+		referenced[id] = true
+		if have[id] != kind {
+			report("missing", objectTypeTag[kind], id, "referenced by "+from)
+		}
+	}
+
+	ids := make([]string, 0, len(have))
+	for id := range have {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		kind := have[id]
+		typ := objectTypeTag[kind]
+		data, err := store.Get(kind, id)
+		if err != nil {
+			report("unreadable", typ, id, err.Error())
+			continue
+		}
+		if got := hashObject(kind, data); got != id {
+			report("hash-mismatch", typ, id, "content hashes to "+got)
+			continue
+		}
+		from := typ + " " + id
+		switch kind {
+		case "commits":
+			c, err := decodeCommit(data)
+			if err != nil {
+				report("unreadable", typ, id, err.Error())
+				continue
+			}
+			for _, p := range commitParents(c) {
+				link(from, "commits", p)
+			}
+			if c.Tree != "" {
+				link(from, "trees", c.Tree)
+			}
+			for _, bid := range c.Files {
+				link(from, "blobs", bid)
+			}
+		case "trees":
+			entries, err := readTree(rp, id)
+			if err != nil {
+				report("unreadable", typ, id, err.Error())
+				continue
+			}
+			for _, e := range entries {
+				link(from, e.Kind+"s", e.ID)
+			}
+		case "tags":
+			t, err := readTag(rp, id)
+			if err != nil {
+				report("unreadable", typ, id, err.Error())
+				continue
+			}
+			link(from, "commits", t.Object)
+		}
+	}
+
+	// refs, the index and merge state; a checked-out branch is checked with
+	// the other branches below
+	if branch, id, err := readHead(rp.root); err != nil {
+		report("bad-ref", "ref", "HEAD", err.Error())
+	} else if branch == "" && have[id] != "commits" {
+		report("bad-ref", "ref", "HEAD", "points at missing commit "+id)
+	}
+	for _, b := range listBranches(rp.root) {
+		ref := "refs/heads/" + b
+		if id := readRef(rp.root, ref); have[id] != "commits" {
+			report("bad-ref", "ref", ref, "points at missing commit "+id)
+		}
+	}
+	names, err := listTags(rp.root)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range names {
+		ref := "refs/tags/" + n
+		if id := readRef(rp.root, ref); have[id] != "commits" && have[id] != "tags" {
+			report("bad-ref", "ref", ref, "points at missing object "+id)
+		}
+	}
+	idx, err := loadIndex(rp.root)
+	if err != nil {
+		return nil, err
+	}
+	staged := make([]string, 0, len(idx.Adds))
+	for f := range idx.Adds {
+		staged = append(staged, f)
+	}
+	sort.Strings(staged)
+	for _, f := range staged {
+		if bid := idx.Adds[f]; have[bid] != "blobs" {
+			report("bad-ref", "ref", "index:"+f, "points at missing blob "+bid)
+		}
+	}
+	if m, err := loadMergeState(rp.root); err != nil {
+		return nil, err
+	} else if m != nil && have[m.Head] != "commits" {
+		report("bad-ref", "ref", "MERGE_HEAD", "points at missing commit "+m.Head)
+	}
+
+	commits, tags, blobs, err := gcRoots(rp)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range [][]string{commits, tags, blobs} {
+		for _, id := range set {
+			referenced[id] = true
+		}
+	}
+	for _, id := range ids {
+		if !referenced[id] {
+			report("dangling", objectTypeTag[have[id]], id, "")
+		}
+	}
+	return problems, nil
+}
+
+// fsckCmd returns every problem fsckRepo finds. Only dangling objects is
+// still a clean result.
+func fsckCmd(rp *repo) ([]FsckProblem, error) {
+	if _, err := rp.open(); err != nil { return nil, err }
+	return fsckRepo(rp)
+}
@@ -0,0 +1,42 @@
+package gitlet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFsckReportsBadRefOnce(t *testing.T) {
+	missing := strings.Repeat("ab", 20)
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantID  string
+	}{
+		{"checked-out branch", ".gitlet/refs/heads/master", missing + "\n", "refs/heads/master"},
+		{"detached HEAD", ".gitlet/HEAD", missing + "\n", "HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := Init(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, dir, tt.file, tt.content)
+			problems, err := r.Fsck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var bad []FsckProblem
+			for _, p := range problems {
+				if p.Code == "bad-ref" {
+					bad = append(bad, p)
+				}
+			}
+			if len(bad) != 1 || bad[0].ID != tt.wantID {
+				t.Fatalf("bad refs = %v, want one for %s", bad, tt.wantID)
+			}
+		})
+	}
+}
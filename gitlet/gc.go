@@ -0,0 +1,290 @@
+package gitlet
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// objectKinds are the directories under objects/, in report order.
+var objectKinds = []string{"blobs", "commits", "trees", "tags"}
+
+// defaultGracePeriod keeps unreachable objects this young, so gc never races
+// a command that has written objects but not yet the ref or index naming them.
+const defaultGracePeriod = 14 * 24 * time.Hour
+
+// looseObject is one object file under objects/<kind>/xx/.
+type looseObject struct {
+	Kind, ID string
+	Size     int64
+	ModTime  time.Time
+}
+
+// listObjects returns every object of kind, sorted by id; temp files left by
+// an interrupted writeAtomic are skipped.
+func listObjects(root, kind string) ([]looseObject, error) {
+	base := filepath.Join(root, "objects", kind)
+	shards, err := os.ReadDir(base)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var objs []looseObject
+	for _, sh := range shards {
+		if !sh.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(base, sh.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() || strings.HasPrefix(f.Name(), ".tmp-") {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, looseObject{Kind: kind, ID: sh.Name() + f.Name(), Size: info.Size(), ModTime: info.ModTime()})
+		}
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i].ID < objs[j].ID })
+	return objs, nil
+}
+
+// gcRoots collects the starting points of the mark phase: commits named by
+// branches, tags, every reflog entry and HEAD (detached or not), annotated
+// tag objects, blobs staged in the index, and an in-progress merge.
+func gcRoots(rp *repo) (commits, tags, blobs []string, err error) {
+	if id, err := headCommitID(rp.root); err == nil && id != "" {
+		commits = append(commits, id)
+	}
+	for _, b := range listBranches(rp.root) {
+		commits = append(commits, readRef(rp.root, "refs/heads/"+b))
+	}
+
+	names, err := listTags(rp.root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, n := range names {
+		id := readRef(rp.root, "refs/tags/"+n)
+		if hasObject(rp, "tags", id) {
+			tags = append(tags, id)
+			continue
+		}
+		commits = append(commits, id)
+	}
+
+	logs := filepath.Join(rp.root, "logs")
+	err = filepath.WalkDir(logs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(rp.root, p)
+		if err != nil {
+			return err
+		}
+		es, err := readReflog(rp.root, strings.TrimPrefix(filepath.ToSlash(rel), "logs/"))
+		if err != nil {
+			return err
+		}
+		for _, e := range es {
+			commits = append(commits, e.Old, e.New)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	idx, err := loadIndex(rp.root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, bid := range idx.Adds {
+		blobs = append(blobs, bid)
+	}
+
+	m, err := loadMergeState(rp.root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if m != nil {
+		commits = append(commits, m.Head)
+		for _, bid := range m.Orig {
+			blobs = append(blobs, bid)
+		}
+	}
+	return commits, tags, blobs, nil
+}
+
+// markReachable returns the ids of every object reachable from the gc roots.
+// A missing reachable object is an error: pruning around a damaged history
+// could only make it worse.
+func markReachable(rp *repo) (map[string]bool, error) {
+	commits, tags, blobs, err := gcRoots(rp)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+
+	for _, id := range tags {
+		if seen[id] {
+			continue
+		}
+		t, err := readTag(rp, id)
+		if err != nil {
+			return nil, err
+		}
+		seen[id] = true
+		commits = append(commits, t.Object)
+	}
+	for _, id := range blobs {
+		if id != "" {
+			seen[id] = true
+		}
+	}
+
+	var markTree func(id string) error
+	markTree = func(id string) error {
+		if id == "" || seen[id] {
+			return nil
+		}
+		entries, err := readTree(rp, id)
+		if err != nil {
+			return err
+		}
+		seen[id] = true
+		for _, e := range entries {
+			if e.Kind == "tree" {
+				if err := markTree(e.ID); err != nil {
+					return err
+				}
+			} else {
+				seen[e.ID] = true
+			}
+		}
+		return nil
+	}
+
+	for len(commits) > 0 {
+		id := commits[len(commits)-1]
+		commits = commits[:len(commits)-1]
+		if id == "" || id == zeroID || seen[id] {
+			continue
+		}
+		c, err := readCommitHeader(rp, id)
+		if err != nil {
+			return nil, fmt.Errorf("Reachable commit %s cannot be read: %w", id, err)
+		}
+		seen[id] = true
+		if c.Tree != "" {
+			if err := markTree(c.Tree); err != nil {
+				return nil, err
+			}
+		}
+		for _, bid := range c.Files {
+			seen[bid] = true
+		}
+		commits = append(commits, commitParents(c)...)
+	}
+	return seen, nil
+}
+
+// GCObject is an object gc removed (or, on a dry run, would remove).
+type GCObject struct {
+	Kind   string // blob, commit, tree or tag
+	ID     string
+	Size   int64 // bytes on disk
+	Packed bool
+}
+
+// GCResult lists what gc removed, with the bytes freed.
+type GCResult struct {
+	Objects []GCObject
+	Bytes   int64
+}
+
+// gcCmd deletes objects that nothing reaches and that are older than the
+// grace period (gc.gracePeriod, a Go duration; grace overrides it when not
+// empty). With dryRun it only reports what would go.
+func gcCmd(rp *repo, dryRun bool, grace string) (*GCResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+	fs, err := rp.fileStore("gc")
+	if err != nil { return nil, err }
+
+	if grace == "" {
+		grace = loadConfig(root).get("gc.graceperiod", "")
+	}
+	period := defaultGracePeriod
+	if grace != "" {
+		if period, err = time.ParseDuration(grace); err != nil || period < 0 {
+			return nil, restate(ErrIncorrectOperands, fmt.Sprintf("Invalid grace period %q.", grace))
+		}
+	}
+	cutoff := time.Now().Add(-period)
+
+	seen, err := markReachable(rp)
+	if err != nil { return nil, err }
+
+	res := &GCResult{}
+	for _, kind := range objectKinds {
+		objs, err := listObjects(root, kind)
+		if err != nil { return nil, err }
+		for _, o := range objs {
+			if seen[o.ID] || o.ModTime.After(cutoff) {
+				continue
+			}
+			res.Objects = append(res.Objects, GCObject{Kind: objectTypeTag[kind], ID: o.ID, Size: o.Size})
+			res.Bytes += o.Size
+			if dryRun {
+				continue
+			}
+			dir, path := objectPath(root, kind, o.ID)
+			if err := os.Remove(path); err != nil { return nil, err }
+			os.Remove(dir) // only succeeds once the shard is empty
+		}
+	}
+
+	// packed objects go by rewriting the packs without them
+	drop := map[string]bool{}
+	ps, err := fs.loadPacks()
+	if err != nil { return nil, err }
+	for _, p := range ps {
+		if time.Unix(p.mod, 0).After(cutoff) {
+			continue
+		}
+		for i := 0; i < p.n; i++ {
+			id := p.id(i)
+			if seen[id] || drop[id] {
+				continue
+			}
+			drop[id] = true
+			res.Objects = append(res.Objects, GCObject{Kind: objectTypeTag[p.kind(i)], ID: id, Size: p.length(i), Packed: true})
+			res.Bytes += p.length(i)
+		}
+	}
+	if !dryRun && len(drop) > 0 {
+		if _, err := repack(rp, drop, false); err != nil { return nil, err }
+	}
+	return res, nil
+}
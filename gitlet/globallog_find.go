@@ -0,0 +1,32 @@
+package gitlet
+
+// globalLogCmd produces every stored commit (see logCmd), sorted by id for
+// stability; unreadable ones are skipped.
+func globalLogCmd(rp *repo) (func() (LogEntry, bool, error), error) {
+	if _, err := rp.open(); err != nil { return nil, err }
+
+	ids, err := listCommitIDs(rp)
+	if err != nil { return nil, err }
+	return commitList(rp, ids, true), nil
+}
+
+// findCmd returns the ids of every commit whose message is msg, sorted, or
+// ErrNoMatchingCommit.
+func findCmd(rp *repo, msg string) ([]string, error) {
+	if _, err := rp.open(); err != nil { return nil, err }
+
+	ids, err := listCommitIDs(rp)
+	if err != nil { return nil, err }
+
+	var found []string
+	for _, id := range ids {
+		c, err := readCommitHeader(rp, id)
+		if err == nil && c.Message == msg {
+			found = append(found, id)
+		}
+	}
+	if len(found) == 0 {
+		return nil, ErrNoMatchingCommit
+	}
+	return found, nil
+}
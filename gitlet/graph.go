@@ -0,0 +1,121 @@
+package gitlet
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// parents of a commit (0, 1, or 2)
+func commitParents(c *Commit) []string {
+	ps := []string{}
+	if c.Parent != "" { ps = append(ps, c.Parent) }
+	if c.SecondParent != "" { ps = append(ps, c.SecondParent) }
+	return ps
+}
+
+// ancestorsOf returns the set of commits reachable from any of starts,
+// including the starts themselves.
+func ancestorsOf(rp *repo, starts []string) (map[string]bool, error) {
+	seen := map[string]bool{}
+	q := list.New()
+	for _, s := range starts {
+		if !seen[s] { seen[s] = true; q.PushBack(s) }
+	}
+	for q.Len() > 0 {
+		id := q.Remove(q.Front()).(string)
+		c, err := readCommitHeader(rp, id)
+		if err != nil { return nil, err }
+		for _, p := range commitParents(c) {
+			if !seen[p] { seen[p] = true; q.PushBack(p) }
+		}
+	}
+	return seen, nil
+}
+
+// bestCommonAncestors returns, sorted, every common ancestor of the two sides
+// that is not itself an ancestor of another common ancestor. Unlike a
+// distance heuristic this is exact, and criss-cross histories yield several.
+func bestCommonAncestors(rp *repo, left, right []string) ([]string, error) {
+	la, err := ancestorsOf(rp, left); if err != nil { return nil, err }
+	ra, err := ancestorsOf(rp, right); if err != nil { return nil, err }
+
+	var common []string
+	for id := range la {
+		if ra[id] { common = append(common, id) }
+	}
+
+	// Everything strictly behind some common ancestor is dominated. Ancestors
+	// of common ancestors are common too, so one walk from all their parents
+	// marks them all.
+	var parents []string
+	for _, id := range common {
+		c, err := readCommitHeader(rp, id)
+		if err != nil { return nil, err }
+		parents = append(parents, commitParents(c)...)
+	}
+	dominated, err := ancestorsOf(rp, parents); if err != nil { return nil, err }
+
+	var best []string
+	for _, id := range common {
+		if !dominated[id] { best = append(best, id) }
+	}
+	sort.Strings(best)
+	return best, nil
+}
+
+// mergeBases returns all best common ancestors of commits a and b.
+func mergeBases(rp *repo, a, b string) ([]string, error) {
+	return bestCommonAncestors(rp, []string{a}, []string{b})
+}
+
+// isAncestor reports whether a is b or reachable from b through parents.
+func isAncestor(rp *repo, a, b string) (bool, error) {
+	anc, err := ancestorsOf(rp, []string{b})
+	if err != nil { return false, err }
+	return anc[a], nil
+}
+
+// virtualMergeBase turns the merge bases into a single base commit. With more
+// than one, they are merged pairwise like git's recursive strategy: each
+// pair's own merge bases (merged recursively) serve as the base, and any
+// conflicts are kept in the virtual snapshot with their markers. The result
+// lives only in memory (plus the blobs for merged contents).
+func virtualMergeBase(rp *repo, bases []string) (*Commit, error) {
+	if len(bases) == 0 {
+		// unrelated histories: merge against an empty snapshot
+		return &Commit{Files: map[string]string{}}, nil
+	}
+	merged, err := readCommit(rp, bases[0])
+	if err != nil { return nil, err }
+	mergedFrom := []string{bases[0]}
+	for _, next := range bases[1:] {
+		inner, err := bestCommonAncestors(rp, mergedFrom, []string{next})
+		if err != nil { return nil, err }
+		innerBase, err := virtualMergeBase(rp, inner)
+		if err != nil { return nil, err }
+		nextC, err := readCommit(rp, next)
+		if err != nil { return nil, err }
+
+		planned, _, err := planMerge(rp, innerBase, merged, nextC, "Temporary merge branch 1", "Temporary merge branch 2")
+		if err != nil { return nil, err }
+		files := make(map[string]string, len(merged.Files))
+		for f, bid := range merged.Files { files[f] = bid }
+		for f, act := range planned {
+			if act.del { delete(files, f) }
+			if act.write { files[f] = act.bid }
+		}
+		merged = &Commit{Files: files}
+		mergedFrom = append(mergedFrom, next)
+	}
+	return merged, nil
+}
+
+// tiny helper: read a branch ref to full id
+func readBranchID(root, name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(root, "refs", "heads", name))
+	if err != nil { return "", err }
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,104 @@
+package gitlet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// .gitlet/HEAD contains either "ref: refs/heads/<name>\n" (on a branch) or a
+// bare commit id (detached HEAD).
+//
+// readHead returns the branch name ("" when detached) and the commit id HEAD
+// resolves to.
+func readHead(root string) (branch, id string, err error) {
+	b, err := os.ReadFile(filepath.Join(root, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+	line := strings.TrimSpace(string(b))
+	const pfx = "ref: "
+	if !strings.HasPrefix(line, pfx) {
+		return "", line, nil // detached
+	}
+	rel := strings.TrimSpace(line[len(pfx):])
+	branch = strings.TrimPrefix(rel, "refs/heads/")
+	ref, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", "", err
+	}
+	return branch, strings.TrimSpace(string(ref)), nil
+}
+
+func headCommitID(root string) (string, error) {
+	_, id, err := readHead(root)
+	return id, err
+}
+
+// currentBranch returns the checked-out branch, or "" when HEAD is detached.
+func currentBranch(root string) (string, error) {
+	branch, _, err := readHead(root)
+	return branch, err
+}
+
+// updateHead moves whatever HEAD stands for from old to id: the current
+// branch, or HEAD itself when detached. reason goes to the reflog.
+func updateHead(root, old, id, reason string) error {
+	branch, err := currentBranch(root)
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return updateRef(root, "HEAD", old, id, reason)
+	}
+	return updateRef(root, "refs/heads/"+branch, old, id, reason)
+}
+
+// listBranches returns the branch names under refs/heads, sorted, leaving
+// out lock and temporary files.
+func listBranches(root string) []string {
+	var names []string
+	ents, _ := os.ReadDir(filepath.Join(root, "refs", "heads"))
+	for _, e := range ents {
+		if !e.IsDir() && !isLockOrTemp(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// refTips returns the commit ids named by every branch and tag.
+func refTips(rp *repo) ([]string, error) {
+	var tips []string
+	for _, b := range listBranches(rp.root) {
+		id, err := readBranchID(rp.root, b)
+		if err != nil {
+			return nil, err
+		}
+		tips = append(tips, id)
+	}
+	tags, err := listTags(rp.root)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		id, err := resolveTag(rp, t)
+		if err != nil {
+			return nil, err
+		}
+		tips = append(tips, id)
+	}
+	return tips, nil
+}
+
+// headName describes HEAD for messages: the branch name, or the short id
+// HEAD is detached at.
+func headName(root, branch string) string {
+	if branch != "" {
+		return branch
+	}
+	if id, err := headCommitID(root); err == nil && len(id) >= 7 {
+		return id[:7]
+	}
+	return "HEAD"
+}
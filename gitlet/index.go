@@ -1,4 +1,4 @@
-package main
+package gitlet
 
 import (
 	"bufio"
@@ -8,13 +8,13 @@ import (
 	"strings"
 )
 
-type Index struct {
+type index struct {
 	Adds    map[string]string   // filename -> blobID
 	Removes map[string]struct{} // set
 }
 
-func newIndex() *Index {
-	return &Index{
+func newIndex() *index {
+	return &index{
 		Adds:    map[string]string{},
 		Removes: map[string]struct{}{},
 	}
@@ -22,7 +22,7 @@ func newIndex() *Index {
 
 func indexPath(root string) string { return filepath.Join(root, "index") }
 
-func loadIndex(root string) (*Index, error) {
+func loadIndex(root string) (*index, error) {
 	idx := newIndex()
 	b, err := os.ReadFile(indexPath(root))
 	if err != nil {
@@ -46,7 +46,12 @@ func loadIndex(root string) (*Index, error) {
 	return idx, nil
 }
 
-func (i *Index) save(root string) error {
+func (i *index) save(root string) error {
+	return writeAtomic(indexPath(root), i.bytes())
+}
+
+// bytes is the index file contents: removals, then additions, each sorted.
+func (i *index) bytes() []byte {
 	var lines []string
 	rm := make([]string, 0, len(i.Removes))
 	for f := range i.Removes {
@@ -64,10 +69,10 @@ func (i *Index) save(root string) error {
 	for _, f := range add {
 		lines = append(lines, "A\t"+f+"\t"+i.Adds[f])
 	}
-	return writeAtomic(indexPath(root), []byte(strings.Join(lines, "\n")))
+	return []byte(strings.Join(lines, "\n"))
 }
 
-func (i *Index) clear() {
+func (i *index) clear() {
 	i.Adds = map[string]string{}
 	i.Removes = map[string]struct{}{}
 }
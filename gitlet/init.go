@@ -0,0 +1,222 @@
+// gitlet/init.go
+
+package gitlet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- Types and helpers for canonical, deterministic commits ----
+
+type Commit struct {
+	Message      string
+	TimestampRFC string // RFC3339 with sub-second precision, in the committer's own UTC offset
+	Parent       string // empty for initial commit
+	SecondParent string // empty unless merge
+	Author       string // "Name <email>"; empty for the initial commit and older commits
+	Committer    string // "Name <email>"; empty for the initial commit and older commits
+	Tree         string // root tree id; empty only for commits written before trees existed
+	Files        map[string]string // filename -> blobID (empty map for initial); expanded from Tree by ReadCommit, left empty in log entries
+}
+
+// CanonicalBytes builds a stable, language-agnostic byte layout.
+// Commits reference their snapshot through the root tree id; commits from
+// before tree objects existed keep their inline, sorted files section.
+// A message with newlines goes last, as in tags, so it can hold them; a
+// one-line message stays first, which keeps the ids of existing commits.
+func (c *Commit) CanonicalBytes() []byte {
+	var b []byte
+	appendKV := func(k, v string) {
+		b = append(b, k...)
+		b = append(b, '\n')
+		b = append(b, v...)
+		b = append(b, '\n')
+	}
+	multiline := strings.Contains(c.Message, "\n")
+	if !multiline {
+		appendKV("message", c.Message)
+	}
+	appendKV("timestamp", c.TimestampRFC)
+	appendKV("parent", c.Parent)
+	appendKV("parent2", c.SecondParent)
+	// Identity lines are only present when set, which keeps the ids of
+	// commits written before they existed (and of the initial commit) stable.
+	if c.Author != "" {
+		appendKV("author", c.Author)
+	}
+	if c.Committer != "" {
+		appendKV("committer", c.Committer)
+	}
+	if c.Tree != "" {
+		appendKV("tree", c.Tree)
+	} else {
+		// Legacy files section: sorted (filename, blobID) lines.
+		b = append(b, "files\n"...)
+		keys := make([]string, 0, len(c.Files))
+		for k := range c.Files {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			line := fmt.Sprintf("%s\t%s\n", k, c.Files[k])
+			b = append(b, line...)
+		}
+	}
+	if multiline {
+		appendKV("message", c.Message)
+	}
+	return b
+}
+
+// commitTimestamp formats t for TimestampRFC, keeping its zone offset so log
+// can show the time as the committer saw it.
+func commitTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseCommitTime parses a stored TimestampRFC (with or without fractional seconds).
+func parseCommitTime(ts string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, ts)
+}
+
+// formatCommitDate renders a stored timestamp in the spec's log layout, in the
+// offset it was recorded with, or in the viewer's zone when viewerLocal is set.
+func formatCommitDate(ts string, viewerLocal bool) string {
+	t, err := parseCommitTime(ts)
+	if err != nil {
+		return ts
+	}
+	if viewerLocal {
+		t = t.In(time.Local)
+	}
+	return t.Format("Mon Jan _2 15:04:05 2006 -0700")
+}
+
+// Date is the commit's timestamp in the log layout (see formatCommitDate).
+func (c *Commit) Date(viewerLocal bool) string {
+	return formatCommitDate(c.TimestampRFC, viewerLocal)
+}
+
+// Subject is the first line of the commit message.
+func (c *Commit) Subject() string {
+	return firstLine(c.Message)
+}
+
+func (c *Commit) ID() string {
+	h := sha1.New()
+	h.Write([]byte("commit\n"))        // type tag to avoid blob/commit collisions
+	h.Write(c.CanonicalBytes())        // canonical payload
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCommit stores c.Files as tree objects, sets c.Tree, and writes the
+// commit object. It returns the new commit id.
+func writeCommit(rp *repo, c *Commit) (string, error) {
+	tid, err := writeTree(rp, c.Files)
+	if err != nil {
+		return "", err
+	}
+	c.Tree = tid
+	cid := c.ID()
+	if err := ensureObjectStored(rp, "commits", cid, c.CanonicalBytes()); err != nil {
+		return "", err
+	}
+	return cid, nil
+}
+
+// ---- Filesystem helpers (safe paths, atomic writes, etc.) ----
+
+func writeAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil {
+		os.Remove(tmpName)
+		return werr
+	}
+	if cerr != nil {
+		os.Remove(tmpName)
+		return cerr
+	}
+	// If destination already exists and has identical bytes, this will replace it,
+	// which is fine (objects are immutable; refs can be updated).
+	return os.Rename(tmpName, path)
+}
+
+// ensureObjectStored puts an immutable object into the repository's store
+// if it isn't there already.
+func ensureObjectStored(rp *repo, kind, id string, data []byte) error {
+	return rp.store.Put(kind, id, data)
+}
+
+// ---- Init command ----
+
+func initCmd(rp *repo) error {
+	root := filepath.Join(rp.dir, ".gitlet")
+	rp.root = root
+
+	// Failure case: already initialized.
+	if st, err := os.Stat(root); err == nil && st.IsDir() {
+		return ErrRepoExists
+	}
+
+	// Create base directories.
+	dirs := []string{
+		root,
+		filepath.Join(root, "refs", "heads"),
+		filepath.Join(root, "refs", "tags"),
+		filepath.Join(root, "objects", "blobs"),
+		filepath.Join(root, "objects", "commits"),
+		filepath.Join(root, "objects", "trees"),
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return err
+		}
+	}
+
+	if err := currentFormat().save(root); err != nil {
+		return err
+	}
+
+	// Build the epoch initial commit: empty snapshot, fixed message, epoch time.
+	initial := &Commit{
+		Message:      "initial commit",
+		TimestampRFC: time.Unix(0, 0).UTC().Format(time.RFC3339), // 1970-01-01T00:00:00Z
+		Parent:       "",
+		SecondParent: "",
+		Files:        map[string]string{},
+	}
+
+	// Write the empty root tree and the commit object (id-sharded paths).
+	cid, err := writeCommit(rp, initial)
+	if err != nil {
+		return err
+	}
+
+	// Write HEAD (symbolic ref) and master tip.
+	if err := setSymbolicHead(root, "master", ""); err != nil {
+		return err
+	}
+	if err := updateRef(root, "refs/heads/master", zeroID, cid, "commit (initial): initial commit"); err != nil {
+		return err
+	}
+
+	// Success: per spec, print nothing.
+	return nil
+}
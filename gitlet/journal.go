@@ -0,0 +1,406 @@
+package gitlet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Commands that rewrite the working tree (checkout, reset, merge and
+// merge --abort) never change it in place piecemeal. They first plan the
+// whole change as a journal: the state before and after of HEAD, the ref
+// being moved, the index, the merge state and every working file touched
+// (by blob id). The journal is written to .gitlet/JOURNAL, applied, and
+// removed:
+//
+//	op <reflog reason>
+//	head <HEAD before>\t<HEAD after>
+//	ref <ref>\t<id before>\t<id after>
+//	index <blob before>\t<blob after>
+//	path <path>\t<blob before>\t<blob after>
+//	merge <before|after> <MERGE_HEAD>\t<quoted message>
+//	conflict <before|after> <path>
+//	orig <before|after> <path>\t<blob>
+//
+// An empty blob id means absent (no index file, file not in the tree). The
+// index files and the old working copies it names are kept in
+// .gitlet/journal/<blob id> rather than the object store, which would keep
+// them as unreachable objects, and are removed with the journal; the other
+// blobs come from commits. A failure while applying rolls back at once; a journal left behind by a
+// crash stops every command until recover rolls it back or, with
+// --continue, finishes it. Both directions are idempotent, so recover can
+// itself be interrupted and rerun.
+const (
+	beforeSide = 0
+	afterSide  = 1
+)
+
+type journal struct {
+	Op     string
+	Head   [2]string // raw HEAD contents: "ref: refs/heads/<b>" or an id
+	Ref    string    // branch moved, if any
+	RefIDs [2]string
+	Index  [2]string
+	Paths  map[string][2]string
+	Merge  [2]*mergeState
+}
+
+var sides = [2]string{"before", "after"}
+
+// InterruptedError is returned while a journal from an interrupted command
+// is still present.
+type InterruptedError struct {
+	Op string
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("An interrupted command (%s) left the working tree half-updated; "+
+		"run recover to roll it back, or recover --continue to finish it.", e.Op)
+}
+
+func journalPath(root string) string { return filepath.Join(root, "JOURNAL") }
+func journalDir(root string) string { return filepath.Join(root, "journal") }
+
+// keep saves data, blob id, for the journal to restore.
+func (j *journal) keep(rp *repo, id string, data []byte) error {
+	if err := os.MkdirAll(journalDir(rp.root), 0o755); err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(journalDir(rp.root), id), data)
+}
+
+// blob returns blob id from those the journal kept, or else from the store.
+func (j *journal) blob(rp *repo, id string) ([]byte, error) {
+	path := filepath.Join(journalDir(rp.root), id)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return readWorktreeBlob(rp, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if got := blobID(data); got != id {
+		return nil, &CorruptObjectError{Kind: "blob", ID: id, Path: path, Got: got}
+	}
+	return data, nil
+}
+
+// endJournal removes the journal, then the files it kept.
+func endJournal(root string) error {
+	if err := os.Remove(journalPath(root)); err != nil {
+		return err
+	}
+	return os.RemoveAll(journalDir(root))
+}
+
+// checkJournal returns an *InterruptedError if a journal is pending.
+func checkJournal(root string) error {
+	j, err := loadJournal(root)
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		return &InterruptedError{Op: j.Op}
+	}
+	return nil
+}
+
+// beginJournal records the current HEAD, index and merge state; until the
+// caller plans changes the journal is a no-op. Files kept for a journal
+// that was planned but never run are cleared first.
+func beginJournal(rp *repo, op string) (*journal, error) {
+	j := &journal{Op: op, Paths: map[string][2]string{}}
+	if err := os.RemoveAll(journalDir(rp.root)); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(rp.root, "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	j.Head[beforeSide] = strings.TrimSpace(string(b))
+	if b, err := os.ReadFile(indexPath(rp.root)); err == nil {
+		j.Index[beforeSide] = blobID(b)
+		if err := j.keep(rp, j.Index[beforeSide], b); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if j.Merge[beforeSide], err = loadMergeState(rp.root); err != nil {
+		return nil, err
+	}
+	j.Head[afterSide], j.Index[afterSide], j.Merge[afterSide] = j.Head[beforeSide], j.Index[beforeSide], j.Merge[beforeSide]
+	return j, nil
+}
+
+// touch plans working file p to end up as blob id ("" to delete it), saving
+// its current contents so the change can be undone.
+func (j *journal) touch(rp *repo, p, id string) error {
+	abs := filepath.Join(rp.dir, filepath.FromSlash(p))
+	old := ""
+	data, err := os.ReadFile(abs)
+	if err == nil {
+		old = blobID(data)
+		if old != id {
+			if err := j.keep(rp, old, data); err != nil {
+				return err
+			}
+		}
+	} else if st, serr := os.Stat(abs); serr == nil && !st.IsDir() {
+		return err // there, but unreadable
+	}
+	if old != id {
+		j.Paths[p] = [2]string{old, id}
+	}
+	return nil
+}
+
+// setIndex plans the index to be replaced by idx.
+func (j *journal) setIndex(rp *repo, idx *index) error {
+	b := idx.bytes()
+	j.Index[afterSide] = blobID(b)
+	return j.keep(rp, j.Index[afterSide], b)
+}
+
+// moveHead plans whatever HEAD stands for to move from old to id: the
+// checked-out branch, or HEAD itself when detached.
+func (j *journal) moveHead(old, id string) {
+	if branch, ok := strings.CutPrefix(j.Head[beforeSide], "ref: refs/heads/"); ok {
+		j.Ref, j.RefIDs = "refs/heads/"+branch, [2]string{old, id}
+		return
+	}
+	j.Head[afterSide] = id
+}
+
+// run writes the journal, applies it and removes it. Everything to be
+// written is read (and verified) first; if applying fails partway the changes
+// already made are rolled back before returning the error.
+func (j *journal) run(rp *repo) error {
+	contents, err := j.contents(rp, afterSide)
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(journalPath(rp.root), j.bytes()); err != nil {
+		return err
+	}
+	if err := j.replay(rp, afterSide, contents); err != nil {
+		undo, uerr := j.contents(rp, beforeSide)
+		if uerr == nil {
+			uerr = j.replay(rp, beforeSide, undo)
+		}
+		if uerr != nil {
+			return fmt.Errorf("%w (rolling back failed too: %v)", err, uerr)
+		}
+		if rerr := endJournal(rp.root); rerr != nil {
+			return rerr
+		}
+		return err
+	}
+	return endJournal(rp.root)
+}
+
+// contents reads the blobs of every working file as of side.
+func (j *journal) contents(rp *repo, side int) (map[string][]byte, error) {
+	out := map[string][]byte{}
+	for p, ids := range j.Paths {
+		if ids[side] == "" {
+			continue
+		}
+		data, err := j.blob(rp, ids[side])
+		if err != nil {
+			return nil, err
+		}
+		out[p] = data
+	}
+	return out, nil
+}
+
+// replay brings the working tree, index, merge state and refs to side,
+// skipping whatever is already there.
+func (j *journal) replay(rp *repo, side int, contents map[string][]byte) error {
+	// removals first, so a file can give way to a directory of the same name
+	for p, ids := range j.Paths {
+		if ids[side] == "" {
+			removeWorkingFile(rp.dir, p)
+		}
+	}
+	for p, data := range contents {
+		if err := writeWorkingFile(rp.dir, p, data); err != nil {
+			return err
+		}
+	}
+
+	if id := j.Index[side]; id == "" {
+		if err := os.Remove(indexPath(rp.root)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		b, err := j.blob(rp, id)
+		if err != nil {
+			return err
+		}
+		if err := writeAtomic(indexPath(rp.root), b); err != nil {
+			return err
+		}
+	}
+	if m := j.Merge[side]; m == nil {
+		if err := clearMergeState(rp.root); err != nil {
+			return err
+		}
+	} else if err := m.save(rp.root); err != nil {
+		return err
+	}
+
+	reason, old := j.Op, j.RefIDs[beforeSide]
+	if side == beforeSide {
+		reason, old = "recover: undo "+j.Op, ""
+	}
+	if j.Ref != "" && readRef(rp.root, j.Ref) != j.RefIDs[side] {
+		if err := updateRef(rp.root, j.Ref, old, j.RefIDs[side], reason); err != nil {
+			return err
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(rp.root, "HEAD"))
+	if err != nil {
+		return err
+	}
+	if head := j.Head[side]; strings.TrimSpace(string(b)) != head {
+		if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+			return setSymbolicHead(rp.root, branch, reason)
+		}
+		return updateRef(rp.root, "HEAD", "", head, reason)
+	}
+	return nil
+}
+
+func (j *journal) bytes() []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "op %s\nhead %s\t%s\n", j.Op, j.Head[beforeSide], j.Head[afterSide])
+	if j.Ref != "" {
+		fmt.Fprintf(&sb, "ref %s\t%s\t%s\n", j.Ref, j.RefIDs[beforeSide], j.RefIDs[afterSide])
+	}
+	fmt.Fprintf(&sb, "index %s\t%s\n", j.Index[beforeSide], j.Index[afterSide])
+	paths := make([]string, 0, len(j.Paths))
+	for p := range j.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "path %s\t%s\t%s\n", p, j.Paths[p][beforeSide], j.Paths[p][afterSide])
+	}
+	for side, m := range j.Merge {
+		if m == nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "merge %s %s\t%s\n", sides[side], m.Head, strconv.Quote(m.Message))
+		for _, f := range m.Conflicts {
+			fmt.Fprintf(&sb, "conflict %s %s\n", sides[side], f)
+		}
+		for f, bid := range m.Orig {
+			fmt.Fprintf(&sb, "orig %s %s\t%s\n", sides[side], f, bid)
+		}
+	}
+	return []byte(sb.String())
+}
+
+// loadJournal returns nil (and no error) when no journal is pending.
+func loadJournal(root string) (*journal, error) {
+	b, err := os.ReadFile(journalPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bad := func(line string) error {
+		return fmt.Errorf("Malformed journal line %q in %s.", line, filepath.ToSlash(journalPath(root)))
+	}
+	j := &journal{Paths: map[string][2]string{}}
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		key, rest, _ := strings.Cut(line, " ")
+		f := strings.Split(rest, "\t")
+		switch key {
+		case "op":
+			j.Op = rest
+		case "head", "index":
+			if len(f) != 2 {
+				return nil, bad(line)
+			}
+			if key == "head" {
+				j.Head = [2]string{f[0], f[1]}
+			} else {
+				j.Index = [2]string{f[0], f[1]}
+			}
+		case "ref":
+			if len(f) != 3 {
+				return nil, bad(line)
+			}
+			j.Ref, j.RefIDs = f[0], [2]string{f[1], f[2]}
+		case "path":
+			if len(f) != 3 {
+				return nil, bad(line)
+			}
+			j.Paths[f[0]] = [2]string{f[1], f[2]}
+		case "merge", "conflict", "orig":
+			name, rest, _ := strings.Cut(rest, " ")
+			side := slices.Index(sides[:], name)
+			if side < 0 {
+				return nil, bad(line)
+			}
+			m := j.Merge[side]
+			if key == "merge" {
+				head, msg, _ := strings.Cut(rest, "\t")
+				text, err := strconv.Unquote(msg)
+				if err != nil {
+					return nil, bad(line)
+				}
+				j.Merge[side] = &mergeState{Head: head, Message: text, Orig: map[string]string{}}
+				continue
+			}
+			if m == nil {
+				return nil, bad(line)
+			}
+			if key == "conflict" {
+				m.Conflicts = append(m.Conflicts, rest)
+			} else if p, bid, ok := strings.Cut(rest, "\t"); ok {
+				m.Orig[p] = bid
+			} else {
+				return nil, bad(line)
+			}
+		default:
+			return nil, bad(line)
+		}
+	}
+	return j, nil
+}
+
+// recoverCmd finishes with the journal an interrupted command left behind:
+// rolling it back, or with forward set completing it. It returns the
+// interrupted command's description.
+func recoverCmd(rp *repo, forward bool) (string, error) {
+	root, err := rp.find()
+	if err != nil { return "", err }
+	if err := checkFormat(root); err != nil { return "", err }
+	unlock, err := lockRepo(root)
+	if err != nil { return "", err }
+	defer unlock()
+
+	j, err := loadJournal(root)
+	if err != nil { return "", err }
+	if j == nil { return "", ErrNothingToRecover }
+
+	side := beforeSide
+	if forward {
+		side = afterSide
+	}
+	contents, err := j.contents(rp, side)
+	if err != nil { return "", err }
+	if err := j.replay(rp, side, contents); err != nil { return "", err }
+	if err := endJournal(root); err != nil { return "", err }
+	return j.Op, nil
+}
@@ -0,0 +1,208 @@
+package gitlet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// journalRepo makes a repository on master with a.txt, and a branch other
+// where a.txt changed and b/c.txt was added.
+func journalRepo(t *testing.T, opts ...Option) (string, *Repository) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := Init(dir, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(msg string, files map[string]string) {
+		t.Helper()
+		for name, data := range files {
+			writeFile(t, dir, name, data)
+			if err := r.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := r.Commit(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit("a1", map[string]string{"a.txt": "a1\n"})
+	if err := r.CreateBranch("other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Checkout("other"); err != nil {
+		t.Fatal(err)
+	}
+	commit("a2", map[string]string{"a.txt": "a2\n", "b/c.txt": "c\n"})
+	if _, err := r.Checkout("master"); err != nil {
+		t.Fatal(err)
+	}
+	return dir, r
+}
+
+func currentBranchOf(t *testing.T, r *Repository) string {
+	t.Helper()
+	st, err := r.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st.Current
+}
+
+func TestJournalRollsBackAFailedCheckout(t *testing.T) {
+	dir, r := journalRepo(t)
+	// an untracked file where other needs a directory makes the checkout
+	// fail while it is writing files
+	writeFile(t, dir, "b", "blocker\n")
+	if _, err := r.Checkout("other"); err == nil {
+		t.Fatal("checkout succeeded through a file in the way")
+	}
+	if got := readFile(t, dir, "a.txt"); got != "a1\n" {
+		t.Errorf("a.txt = %q after rollback, want a1", got)
+	}
+	if got := readFile(t, dir, "b"); got != "blocker\n" {
+		t.Errorf("b = %q after rollback", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitlet", "JOURNAL")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal left behind: %v", err)
+	}
+	if got := currentBranchOf(t, r); got != "master" {
+		t.Errorf("current branch %q after rollback, want master", got)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		forward    bool
+		wantA      string
+		wantC      bool
+		wantBranch string
+	}{
+		{false, "a1\n", false, "master"},
+		{true, "a2\n", true, "other"},
+	}
+	for _, tt := range tests {
+		name := "rollback"
+		if tt.forward {
+			name = "continue"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir, r := journalRepo(t)
+			crashCheckout(t, r, "other")
+
+			var interrupted *InterruptedError
+			if _, err := r.Status(); !errors.As(err, &interrupted) {
+				t.Fatalf("status after a crash: %v, want an InterruptedError", err)
+			}
+			op, err := r.Recover(tt.forward)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if op != "checkout: moving from master to other" {
+				t.Errorf("recover reports %q", op)
+			}
+
+			if got := readFile(t, dir, "a.txt"); got != tt.wantA {
+				t.Errorf("a.txt = %q, want %q", got, tt.wantA)
+			}
+			_, err = os.Stat(filepath.Join(dir, "b", "c.txt"))
+			if hasC := err == nil; hasC != tt.wantC {
+				t.Errorf("b/c.txt present = %v, want %v", hasC, tt.wantC)
+			}
+			if got := currentBranchOf(t, r); got != tt.wantBranch {
+				t.Errorf("current branch %q, want %q", got, tt.wantBranch)
+			}
+			if _, err := r.Recover(tt.forward); !errors.Is(err, ErrNothingToRecover) {
+				t.Errorf("second recover: %v, want ErrNothingToRecover", err)
+			}
+		})
+	}
+}
+
+// The journal's own copies of the index and working files never reach the
+// object store, where nothing would refer to them.
+func TestJournalLeavesNoObjects(t *testing.T) {
+	dir, r := journalRepo(t)
+	writeFile(t, dir, "a.txt", "a1\n")
+	if _, err := r.Checkout("other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reset("master"); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := r.Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("fsck: %s", p)
+	}
+	if _, err := os.Stat(journalDir(filepath.Join(dir, ".gitlet"))); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal files left behind: %v", err)
+	}
+}
+
+// Rolling back needs only what the journal kept, so it works after a crash
+// that took an in-memory store with it.
+func TestRecoverWithoutTheStore(t *testing.T) {
+	dir, r := journalRepo(t, WithObjectStore(NewMemoryStore()))
+	root := filepath.Join(dir, ".gitlet")
+	index := readFile(t, root, "index")
+	crashCheckout(t, r, "other")
+
+	r, err := Open(dir, WithObjectStore(NewMemoryStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Recover(false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, dir, "a.txt"); got != "a1\n" {
+		t.Errorf("a.txt = %q, want a1", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b", "c.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("b/c.txt after rollback: %v", err)
+	}
+	if got := readFile(t, root, "index"); got != index {
+		t.Errorf("index = %q, want %q", got, index)
+	}
+	if got := readFile(t, root, "HEAD"); got != "ref: refs/heads/master\n" {
+		t.Errorf("HEAD = %q", got)
+	}
+}
+
+// crashCheckout plans a checkout of branch the way checkout does, writes its
+// journal and changes one working file, as if the process died there.
+func crashCheckout(t *testing.T, r *Repository, branch string) {
+	t.Helper()
+	rp := r.op()
+	if _, err := rp.open(); err != nil {
+		t.Fatal(err)
+	}
+	readTip := func(rev string) *Commit {
+		id, err := resolveRevision(rp, rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := readCommit(rp, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	curr, target := readTip("HEAD"), readTip(branch)
+	j, err := beginJournal(rp, "checkout: moving from master to "+branch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Head[afterSide] = "ref: refs/heads/" + branch
+	if err := planSwitch(rp, j, curr, target); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeAtomic(journalPath(rp.root), j.bytes()); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, rp.dir, "a.txt", "a2\n")
+}
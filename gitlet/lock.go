@@ -0,0 +1,181 @@
+package gitlet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Commands that change the repository hold .gitlet/index.lock for their whole
+// run, and every ref write additionally holds <ref>.lock while it compares
+// and swaps the ref. A lock file is created exclusively and names its owner:
+//
+//	pid <n>
+//	host <hostname>
+//	time <RFC3339>
+//
+// A lock is stale when its owner ran on this host and is gone, or when it is
+// older than staleLockAge (owner on another host, or unreadable); stale locks
+// are removed and taken over.
+const staleLockAge = 10 * time.Minute
+
+// LockedError reports a lock held by another live process.
+type LockedError struct {
+	Path  string
+	PID   int
+	Host  string
+	Since string
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("Another gitlet process (pid %d on %s, since %s) is using this repository; "+
+		"wait for it to finish, or remove %s if no such process is running.", e.PID, e.Host, e.Since, filepath.ToSlash(e.Path))
+}
+
+// RefMovedError is returned when a ref changed between being read and being
+// updated, i.e. another process moved it underneath this one.
+type RefMovedError struct {
+	Ref, Expected, Actual string
+}
+
+func (e *RefMovedError) Error() string {
+	short := func(id string) string {
+		if id == "" {
+			return "nothing"
+		}
+		if len(id) > 7 {
+			return id[:7]
+		}
+		return id
+	}
+	return fmt.Sprintf("%s was moved by another process (expected %s, found %s); not updating it.",
+		e.Ref, short(e.Expected), short(e.Actual))
+}
+
+// acquireLock creates path exclusively, taking over a stale lock once. The
+// returned function releases it.
+//
+// Another process may take over the same stale lock and create a fresh one
+// between our judging it and removing it, so the takeover never unlinks path
+// itself: it renames whatever is there aside, checks that the renamed file is
+// the one judged stale, and only then deletes it. A live lock moved aside by
+// mistake is linked back into place.
+func acquireLock(path string) (func(), error) {
+	host, _ := os.Hostname()
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "pid %d\nhost %s\ntime %s\n", os.Getpid(), host, time.Now().Format(time.RFC3339))
+			if err := f.Close(); err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		held, seen, stale := lockOwner(path, host)
+		if !stale || attempt > 0 {
+			return nil, held
+		}
+		if seen == nil {
+			continue // released meanwhile
+		}
+		if err := takeOverLock(path, seen); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// lockFile is what lockOwner saw at a lock's path.
+type lockFile struct {
+	info os.FileInfo
+	data []byte
+}
+
+// takeOverLock removes the stale lock seen at path. The file is first renamed
+// to a unique temporary name; if it turns out not to be seen (a new owner
+// replaced it), it is linked back unless path was taken again meanwhile.
+func takeOverLock(path string, seen *lockFile) error {
+	aside := filepath.Join(filepath.Dir(path), fmt.Sprintf(".tmp-%s.%d.%d", filepath.Base(path), os.Getpid(), time.Now().UnixNano()))
+	if err := os.Rename(path, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	info, err := os.Stat(aside)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(aside)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(info, seen.info) && bytes.Equal(data, seen.data) {
+		return os.Remove(aside)
+	}
+	if err := os.Link(aside, path); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	return os.Remove(aside)
+}
+
+// lockOwner describes who holds the lock at path and whether it is stale,
+// along with the file it judged (nil when the lock was released meanwhile).
+func lockOwner(path, host string) (*LockedError, *lockFile, bool) {
+	e := &LockedError{Path: path, Host: "unknown host", Since: "unknown time"}
+	st, err := os.Stat(path)
+	if err != nil {
+		return e, nil, errors.Is(err, os.ErrNotExist) // released meanwhile: just retry
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		// gone: just retry; otherwise it cannot be checked before removal
+		return e, nil, errors.Is(err, os.ErrNotExist)
+	}
+	seen := &lockFile{info: st, data: b}
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, _ := strings.Cut(line, " ")
+		switch k {
+		case "pid":
+			e.PID, _ = strconv.Atoi(v)
+		case "host":
+			e.Host = v
+		case "time":
+			e.Since = v
+		}
+	}
+	if e.PID > 0 && e.Host == host {
+		return e, seen, !processAlive(e.PID)
+	}
+	return e, seen, time.Since(st.ModTime()) > staleLockAge
+}
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// isLockOrTemp matches lock files and writeAtomic's temporary files, which
+// live next to the refs they guard.
+func isLockOrTemp(name string) bool {
+	return strings.HasSuffix(name, ".lock") || strings.HasPrefix(name, ".tmp-")
+}
+
+// lockRepo takes the repository-wide lock for a command that changes the
+// index, refs or working tree.
+func lockRepo(root string) (func(), error) {
+	return acquireLock(filepath.Join(root, "index.lock"))
+}
@@ -0,0 +1,102 @@
+package gitlet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	host, _ := os.Hostname()
+	long := time.Now().Add(-2 * staleLockAge)
+	tests := []struct {
+		name       string
+		content    string
+		mtime      time.Time
+		wantLocked bool
+	}{
+		{"free", "", time.Time{}, false},
+		{"live owner", fmt.Sprintf("pid %d\nhost %s\ntime x\n", os.Getpid(), host), time.Now(), true},
+		{"recent, other host", "pid 1\nhost elsewhere\ntime x\n", time.Now(), true},
+		{"old, other host", "pid 1\nhost elsewhere\ntime x\n", long, false},
+		{"old, unparseable", "garbage", long, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "index.lock")
+			if tt.content != "" {
+				writeFile(t, dir, "index.lock", tt.content)
+				if err := os.Chtimes(path, tt.mtime, tt.mtime); err != nil {
+					t.Fatal(err)
+				}
+			}
+			release, err := acquireLock(path)
+			var locked *LockedError
+			if tt.wantLocked {
+				if !errors.As(err, &locked) {
+					t.Fatalf("err = %v, want a LockedError", err)
+				}
+				if got := readFile(t, dir, "index.lock"); got != tt.content {
+					t.Fatalf("held lock changed to %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, dir, "index.lock"); !strings.HasPrefix(got, fmt.Sprintf("pid %d\n", os.Getpid())) {
+				t.Fatalf("lock holds %q, want our pid", got)
+			}
+			release()
+			assertOnlyFiles(t, dir)
+		})
+	}
+}
+
+func TestTakeOverLockKeepsAReplacedLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.lock")
+	long := time.Now().Add(-2 * staleLockAge)
+	writeFile(t, dir, "index.lock", "pid 1\nhost elsewhere\ntime x\n")
+	if err := os.Chtimes(path, long, long); err != nil {
+		t.Fatal(err)
+	}
+	_, seen, stale := lockOwner(path, "here")
+	if !stale || seen == nil {
+		t.Fatalf("lockOwner: stale %v, seen %v", stale, seen)
+	}
+
+	// another process takes the stale lock over first and holds a fresh one
+	removeFile(t, dir, "index.lock")
+	fresh := "pid 2\nhost elsewhere\ntime y\n"
+	writeFile(t, dir, "index.lock", fresh)
+
+	if err := takeOverLock(path, seen); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, dir, "index.lock"); got != fresh {
+		t.Fatalf("lock holds %q, want the fresh lock %q", got, fresh)
+	}
+	assertOnlyFiles(t, dir, "index.lock")
+}
+
+// assertOnlyFiles fails unless dir holds exactly the named files.
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	es, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range es {
+		got = append(got, e.Name())
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Fatalf("%s holds %v, want %v", dir, got, names)
+	}
+}
@@ -0,0 +1,70 @@
+package gitlet
+
+import "strings"
+
+// LogEntry is one commit produced by log and global-log. Walks do not read
+// trees, so Commit.Files is empty unless the commit predates them.
+type LogEntry struct {
+	ID string
+	*Commit
+}
+
+// logCmd resolves rev (default HEAD) and returns a function producing the
+// commits log shows, one per call, with ok false after the last. It follows
+// first parents; a range (a..b, a...b) produces every commit in it instead,
+// newest first.
+func logCmd(rp *repo, rev string) (next func() (e LogEntry, ok bool, err error), err error) {
+	if _, err := rp.open(); err != nil {
+		return nil, err
+	}
+	if strings.Contains(rev, "..") {
+		include, exclude, err := resolveRange(rp, rev)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := rangeCommits(rp, include, exclude)
+		if err != nil {
+			return nil, err
+		}
+		return commitList(rp, ids, false), nil
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	id, err := resolveRevision(rp, rev)
+	if err != nil {
+		return nil, err
+	}
+	return func() (LogEntry, bool, error) {
+		if id == "" {
+			return LogEntry{}, false, nil
+		}
+		c, err := readCommitHeader(rp, id)
+		if err != nil {
+			return LogEntry{}, false, err
+		}
+		e := LogEntry{ID: id, Commit: c}
+		id = c.Parent
+		return e, true, nil
+	}, nil
+}
+
+// commitList produces the commits ids names in order; with skipBad, ones
+// that cannot be read are passed over instead of ending with an error.
+func commitList(rp *repo, ids []string, skipBad bool) func() (LogEntry, bool, error) {
+	return func() (LogEntry, bool, error) {
+		for len(ids) > 0 {
+			id := ids[0]
+			ids = ids[1:]
+			c, err := readCommitHeader(rp, id)
+			if err != nil && skipBad {
+				continue
+			}
+			if err != nil {
+				return LogEntry{}, false, err
+			}
+			return LogEntry{ID: id, Commit: c}, true, nil
+		}
+		return LogEntry{}, false, nil
+	}
+}
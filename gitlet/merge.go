@@ -0,0 +1,291 @@
+// gitlet/merge.go
+package gitlet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MergeOutcome says what a merge did.
+type MergeOutcome int
+
+const (
+	MergeUpToDate    MergeOutcome = iota // the given commit is already an ancestor of HEAD
+	MergeFastForward                     // HEAD moved forward to the given commit
+	MergeCommitted                       // a two-parent merge commit was made
+	MergeConflicted                      // conflicts were left to resolve; nothing was committed
+)
+
+// MergeResult is what mergeCmd reports.
+type MergeResult struct {
+	Outcome   MergeOutcome
+	Commit    string   // HEAD afterwards
+	Conflicts []string // conflicted paths, sorted (MergeConflicted only)
+}
+
+func mergeCmd(rp *repo, otherBranch string) (*MergeResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+
+	// branch (or tag, or any other revision) exists?
+	otherID, err := readBranchID(root, otherBranch)
+	if err != nil {
+		rev, rerr := resolveRevision(rp, otherBranch)
+		if rerr != nil { return nil, ErrNoSuchBranch }
+		otherID = rev
+	}
+
+	// self-merge?
+	currBranch, err := currentBranch(root)
+	if err != nil { return nil, err }
+	if currBranch == "" {
+		currBranch = "HEAD" // detached: label and message use HEAD
+	} else if otherBranch == currBranch {
+		return nil, ErrMergeWithSelf
+	}
+
+	// unfinished merge?
+	if m, err := loadMergeState(root); err != nil {
+		return nil, err
+	} else if m != nil {
+		return nil, ErrMergeInProgress
+	}
+
+	// uncommitted changes?
+	idx, _ := loadIndex(root)
+	if len(idx.Adds) > 0 || len(idx.Removes) > 0 {
+		return nil, ErrUncommittedChanges
+	}
+
+	// ids & commits
+	currID, err := headCommitID(root); if err != nil { return nil, err }
+	curr, err := readCommit(rp, currID); if err != nil { return nil, err }
+	other, err := readCommit(rp, otherID); if err != nil { return nil, err }
+
+	// split point(s)
+	bases, err := mergeBases(rp, currID, otherID); if err != nil { return nil, err }
+	if len(bases) == 1 && bases[0] == otherID {
+		return &MergeResult{Outcome: MergeUpToDate, Commit: currID}, nil
+	}
+	if len(bases) == 1 && bases[0] == currID {
+		if _, err := resetTo(rp, otherID, "merge "+otherBranch+": Fast-forward"); err != nil { return nil, err }
+		return &MergeResult{Outcome: MergeFastForward, Commit: otherID}, nil
+	}
+	// several best common ancestors are first merged into one virtual base
+	sp, err := virtualMergeBase(rp, bases); if err != nil { return nil, err }
+
+	// ---------- Decide actions per file ----------
+	planned, encounteredConflict, err := planMerge(rp, sp, curr, other, currBranch, otherBranch)
+	if err != nil { return nil, err }
+
+	// ---------- Pre-check: untracked file in the way ----------
+	for f, act := range planned {
+		if !act.write { continue }
+		abs := filepath.Join(rp.dir, filepath.FromSlash(f))
+		if _, err := os.Stat(abs); err == nil {
+			_, trackedNow := curr.Files[f]
+			// idx is empty (we checked), so "untracked" = !trackedNow
+			if !trackedNow {
+				data, rerr := os.ReadFile(abs)
+				if rerr != nil || blobID(data) != act.bid {
+					return nil, ErrUntrackedInTheWay
+				}
+			}
+		}
+	}
+
+	// ---------- Remember pre-merge working copies for merge --abort ----------
+	orig := make(map[string]string, len(planned))
+	for f := range planned {
+		data, err := os.ReadFile(filepath.Join(rp.dir, filepath.FromSlash(f)))
+		if err != nil {
+			orig[f] = "" // absent before the merge
+			continue
+		}
+		bid := blobID(data)
+		if err := ensureBlobStored(rp, bid, data); err != nil { return nil, err }
+		orig[f] = bid
+	}
+
+	// ---------- Plan working dir changes + build new snapshot ----------
+	j, err := beginJournal(rp, "merge "+otherBranch)
+	if err != nil { return nil, err }
+	newSnap := make(map[string]string, len(curr.Files))
+	for k, v := range curr.Files { newSnap[k] = v }
+	for f, act := range planned {
+		switch {
+		case act.del:
+			if err := j.touch(rp, f, ""); err != nil { return nil, err }
+			delete(newSnap, f)
+		case act.write:
+			if err := j.touch(rp, f, act.bid); err != nil { return nil, err }
+			newSnap[f] = act.bid
+		}
+	}
+
+	msg := fmt.Sprintf("Merged %s into %s.", otherBranch, currBranch)
+
+	// ---------- Conflicts: stop before committing ----------
+	if encounteredConflict {
+		// stage the cleanly merged paths; conflicted ones wait for add/rm
+		m := &mergeState{Head: otherID, Message: msg, Orig: orig}
+		for f, act := range planned {
+			switch {
+			case act.conf:
+				m.Conflicts = append(m.Conflicts, f)
+			case act.del:
+				idx.Removes[f] = struct{}{}
+			case act.write:
+				idx.Adds[f] = act.bid
+			}
+		}
+		if err := j.setIndex(rp, idx); err != nil { return nil, err }
+		j.Merge[afterSide] = m
+		if err := j.run(rp); err != nil { return nil, err }
+		sort.Strings(m.Conflicts)
+		return &MergeResult{Outcome: MergeConflicted, Commit: currID, Conflicts: m.Conflicts}, ErrMergeConflict
+	}
+
+	// If nothing changed, echo the normal commit error
+	if equalSnapshots(newSnap, curr.Files) {
+		return nil, ErrNothingToCommit
+	}
+
+	// ---------- Write merge commit (two parents) ----------
+	c := &Commit{
+		Message:      msg,
+		TimestampRFC: commitTimestamp(time.Now()),
+		Parent:       currID,
+		SecondParent: otherID,
+		Files:        newSnap,
+	}
+	stampIdentity(root, c)
+	cid, err := writeCommit(rp, c)
+	if err != nil { return nil, err }
+
+	// advance current branch ref (or a detached HEAD) and clear the index
+	// (merge auto-staged then committed) together with the working tree
+	j.Op = "merge " + otherBranch + ": Merge made by the recursive strategy."
+	j.moveHead(currID, cid)
+	if err := j.setIndex(rp, newIndex()); err != nil { return nil, err }
+	if err := j.run(rp); err != nil { return nil, err }
+	return &MergeResult{Outcome: MergeCommitted, Commit: cid}, nil
+}
+
+// mergeAction is the planned working-tree change for one path.
+type mergeAction struct {
+	write bool   // write/replace with this blob
+	del   bool   // delete
+	bid   string // blob to write (for write)
+	conf  bool   // was conflict content synthesized
+}
+
+// planMerge decides, per path changed since the split point sp, how to
+// combine curr and other. Merged and conflicted contents are stored as blobs;
+// the working tree is not touched. It reports whether any path conflicted.
+func planMerge(rp *repo, sp, curr, other *Commit, oursLabel, theirsLabel string) (map[string]mergeAction, bool, error) {
+	// only files changed on at least one side since the split point need a
+	// decision; identical subtrees are skipped without being read
+	union := map[string]struct{}{}
+	curDiff, err := diffCommits(rp, sp, curr); if err != nil { return nil, false, err }
+	givDiff, err := diffCommits(rp, sp, other); if err != nil { return nil, false, err }
+	for f := range curDiff { union[f] = struct{}{} }
+	for f := range givDiff { union[f] = struct{}{} }
+
+	planned := map[string]mergeAction{}
+	encounteredConflict := false
+
+	eq := func(a, b string) bool { return a == b }
+	modSince := func(now, base string) bool { return now != base }
+
+	for f := range union {
+		spB := sp.Files[f]
+		curB := curr.Files[f]
+		givB := other.Files[f]
+
+		curMod := modSince(curB, spB)
+		givMod := modSince(givB, spB)
+
+		switch {
+		// same change or both removed: no-op
+		case eq(curB, givB):
+			// nothing
+
+		// modified in given only -> take given
+		case givMod && !curMod:
+			planned[f] = mergeAction{write: true, bid: givB}
+
+		// modified in current only -> keep current
+		case curMod && !givMod:
+			// no-op
+
+		// present at split, unmodified in current, absent in given -> remove
+		case spB != "" && curB == spB && givB == "":
+			planned[f] = mergeAction{del: true}
+
+		// present at split, unmodified in given, absent in current -> remain absent
+		case spB != "" && givB == spB && curB == "":
+			// no-op
+
+		// not at split; only in given -> add
+		case spB == "" && givB != "" && curB == "":
+			planned[f] = mergeAction{write: true, bid: givB}
+
+		// not at split; only in current -> keep
+		case spB == "" && curB != "" && givB == "":
+			// no-op
+
+		default:
+			// changed on both sides: line-merge against the split point,
+			// marking only the regions that truly conflict
+			curData := []byte{}
+			givData := []byte{}
+			spData := []byte{}
+			if curB != "" {
+				d, err := readWorktreeBlob(rp, curB)
+				if err != nil { return nil, false, err }
+				curData = d
+			}
+			if givB != "" {
+				d, err := readWorktreeBlob(rp, givB)
+				if err != nil { return nil, false, err }
+				givData = d
+			}
+			if spB != "" {
+				d, err := readWorktreeBlob(rp, spB)
+				if err != nil { return nil, false, err }
+				spData = d
+			}
+
+			var merged []byte
+			conflicted := true
+			if curB == "" || givB == "" || isBinary(curData) || isBinary(givData) || isBinary(spData) {
+				// modify/delete or binary: no line structure to merge
+				merged = []byte(conflictRegion(string(curData), string(givData), oursLabel, theirsLabel))
+			} else {
+				merged, conflicted = merge3(spData, curData, givData, spB == "", oursLabel, theirsLabel)
+			}
+
+			bid := blobID(merged)
+			if err := ensureBlobStored(rp, bid, merged); err != nil { return nil, false, err }
+			planned[f] = mergeAction{write: true, bid: bid, conf: conflicted}
+			if conflicted { encounteredConflict = true }
+		}
+	}
+	return planned, encounteredConflict, nil
+}
+
+func equalSnapshots(a, b map[string]string) bool {
+	if len(a) != len(b) { return false }
+	for k, v := range a {
+		if b[k] != v { return false }
+	}
+	return true
+}
+
@@ -0,0 +1,102 @@
+package gitlet
+
+import "strings"
+
+// matchMap returns, for each line of base, the index of the line it is
+// matched with in other (-1 if base's line was deleted or changed).
+func matchMap(base, other []string) []int {
+	m := make([]int, len(base))
+	for i := range m {
+		m[i] = -1
+	}
+	for _, e := range diffLines(base, other) {
+		if e.Op == '=' {
+			m[e.A] = e.B
+		}
+	}
+	return m
+}
+
+// merge3 is a diff3-style line merge of ours and theirs against their common
+// base. Base lines kept by both sides act as sync points; between them each
+// side's chunk is taken when only that side changed, either when both made
+// the same change, and a marked conflict region otherwise. With noBase (an
+// add/add, where base is empty) no side can be "unchanged", so every region
+// where the two sides differ conflicts. It reports whether any region
+// conflicted.
+func merge3(base, ours, theirs []byte, noBase bool, oursLabel, theirsLabel string) ([]byte, bool) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matchMap(b, o), matchMap(b, t)
+
+	var out strings.Builder
+	conflict := false
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		// Next base line kept by both sides (or the end of all three files).
+		i2, j2, k2 := i, len(o), len(t)
+		for ; i2 < len(b); i2++ {
+			if mo[i2] >= 0 && mt[i2] >= 0 {
+				j2, k2 = mo[i2], mt[i2]
+				break
+			}
+		}
+
+		if i2 == i && j2 == j && k2 == k {
+			// stable line: unchanged on both sides
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		bc, oc, tc := b[i:i2], o[j:j2], t[k:k2]
+		switch {
+		case equalLines(oc, tc):
+			writeLines(&out, oc)
+		case !noBase && equalLines(oc, bc):
+			writeLines(&out, tc)
+		case !noBase && equalLines(tc, bc):
+			writeLines(&out, oc)
+		default:
+			conflict = true
+			out.WriteString(conflictRegion(strings.Join(oc, ""), strings.Join(tc, ""), oursLabel, theirsLabel))
+		}
+		i, j, k = i2, j2, k2
+	}
+	return []byte(out.String()), conflict
+}
+
+// conflictRegion wraps both sides in <<<<<<< / ======= / >>>>>>> markers,
+// making sure every marker starts on its own line.
+func conflictRegion(ours, theirs, oursLabel, theirsLabel string) string {
+	var sb strings.Builder
+	sb.WriteString("<<<<<<< " + oursLabel + "\n")
+	sb.WriteString(ours)
+	if ours != "" && !strings.HasSuffix(ours, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("=======\n")
+	sb.WriteString(theirs)
+	if theirs != "" && !strings.HasSuffix(theirs, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(">>>>>>> " + theirsLabel + "\n")
+	return sb.String()
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, l := range lines {
+		sb.WriteString(l)
+	}
+}
@@ -0,0 +1,75 @@
+package gitlet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		noBase             bool
+		want               string
+		wantConflict       bool
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", "a\nb\n", false, "a\nb\n", false},
+		{"only ours changed", "a\nb\nc\n", "a\nB\nc\n", "a\nb\nc\n", false, "a\nB\nc\n", false},
+		{"only theirs changed", "a\nb\nc\n", "a\nb\nc\n", "a\nb\nC\n", false, "a\nb\nC\n", false},
+		{"both made the same change", "a\nb\nc\n", "a\nX\nc\n", "a\nX\nc\n", false, "a\nX\nc\n", false},
+		{"separate regions", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", false, "A\nb\nc\nd\nE\n", false},
+		{"overlapping change", "a\nb\nc\n", "a\nO\nc\n", "a\nT\nc\n", false,
+			"a\n<<<<<<< HEAD\nO\n=======\nT\n>>>>>>> other\nc\n", true},
+		{"add/add identical", "", "x\ny\n", "x\ny\n", true, "x\ny\n", false},
+		{"add/add different", "", "x\n", "y\n", true, "<<<<<<< HEAD\nx\n=======\ny\n>>>>>>> other\n", true},
+		{"add/add ours empty", "", "", "y\n", true, "<<<<<<< HEAD\n=======\ny\n>>>>>>> other\n", true},
+		{"add/add theirs empty", "", "x\n", "", true, "<<<<<<< HEAD\nx\n=======\n>>>>>>> other\n", true},
+		{"empty base file changed on one side", "", "", "y\n", false, "y\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := merge3([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), tt.noBase, "HEAD", "other")
+			if string(got) != tt.want || conflict != tt.wantConflict {
+				t.Fatalf("merge3 = %q, %v; want %q, %v", got, conflict, tt.want, tt.wantConflict)
+			}
+		})
+	}
+}
+
+func TestMergeAddAddWithEmptySideConflicts(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateBranch("other"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "f.txt", "")
+	if err := r.Add("f.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Commit("add empty f"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Checkout("other"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "f.txt", "theirs\n")
+	if err := r.Add("f.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Commit("add f"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Checkout("master"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Merge("other"); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("merge: %v, want a conflict", err)
+	}
+	want := "<<<<<<< master\n=======\ntheirs\n>>>>>>> other\n"
+	if got := readFile(t, dir, "f.txt"); got != want {
+		t.Fatalf("f.txt = %q, want %q", got, want)
+	}
+}
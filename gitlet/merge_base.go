@@ -0,0 +1,28 @@
+package gitlet
+
+// mergeBaseCmd returns a best common ancestor of two commits, or every one
+// of them with all set.
+func mergeBaseCmd(rp *repo, a, b string, all bool) ([]string, error) {
+	if _, err := rp.open(); err != nil { return nil, err }
+	aID, err := resolveRevision(rp, a)
+	if err != nil { return nil, err }
+	bID, err := resolveRevision(rp, b)
+	if err != nil { return nil, err }
+
+	bases, err := mergeBases(rp, aID, bID)
+	if err != nil { return nil, err }
+	if !all && len(bases) > 1 {
+		bases = bases[:1]
+	}
+	return bases, nil
+}
+
+// isAncestorCmd reports whether a is an ancestor of (or equal to) b.
+func isAncestorCmd(rp *repo, a, b string) (bool, error) {
+	if _, err := rp.open(); err != nil { return false, err }
+	aID, err := resolveRevision(rp, a)
+	if err != nil { return false, err }
+	bID, err := resolveRevision(rp, b)
+	if err != nil { return false, err }
+	return isAncestor(rp, aID, bID)
+}
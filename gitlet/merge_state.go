@@ -0,0 +1,139 @@
+package gitlet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mergeState is what a conflicted merge leaves behind in .gitlet until it is
+// committed or aborted:
+//
+//	MERGE_HEAD       id of the commit being merged in
+//	MERGE_MSG        message for the eventual merge commit
+//	MERGE_CONFLICTS  conflicted paths not yet resolved with add/rm, one per line
+//	MERGE_ORIG       "path\tblobID" pre-merge working copies of every touched
+//	                 path (empty blobID = file was absent), used by --abort
+type mergeState struct {
+	Head      string
+	Message   string
+	Conflicts []string
+	Orig      map[string]string
+}
+
+func mergeFile(root, name string) string { return filepath.Join(root, name) }
+
+// loadMergeState returns nil (and no error) when no merge is in progress.
+func loadMergeState(root string) (*mergeState, error) {
+	head, err := os.ReadFile(mergeFile(root, "MERGE_HEAD"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &mergeState{Head: strings.TrimSpace(string(head)), Orig: map[string]string{}}
+	if b, err := os.ReadFile(mergeFile(root, "MERGE_MSG")); err == nil {
+		m.Message = strings.TrimRight(string(b), "\n")
+	}
+	if b, err := os.ReadFile(mergeFile(root, "MERGE_CONFLICTS")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if line != "" {
+				m.Conflicts = append(m.Conflicts, line)
+			}
+		}
+	}
+	if b, err := os.ReadFile(mergeFile(root, "MERGE_ORIG")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if p := strings.SplitN(line, "\t", 2); len(p) == 2 {
+				m.Orig[p[0]] = p[1]
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *mergeState) save(root string) error {
+	sort.Strings(m.Conflicts)
+	var orig []string
+	for f, bid := range m.Orig {
+		orig = append(orig, f+"\t"+bid)
+	}
+	sort.Strings(orig)
+	files := map[string]string{
+		"MERGE_MSG":       m.Message + "\n",
+		"MERGE_CONFLICTS": strings.Join(m.Conflicts, "\n"),
+		"MERGE_ORIG":      strings.Join(orig, "\n"),
+		// written last: its presence is what marks the merge as in progress
+		"MERGE_HEAD": m.Head + "\n",
+	}
+	for _, name := range []string{"MERGE_MSG", "MERGE_CONFLICTS", "MERGE_ORIG", "MERGE_HEAD"} {
+		if err := writeAtomic(mergeFile(root, name), []byte(files[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clearMergeState(root string) error {
+	// MERGE_HEAD goes first so a half-cleared state never looks like a merge.
+	for _, name := range []string{"MERGE_HEAD", "MERGE_MSG", "MERGE_CONFLICTS", "MERGE_ORIG"} {
+		if err := os.Remove(mergeFile(root, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// markResolved drops filename from the conflict list of an in-progress merge;
+// add and rm call it so staging a file counts as resolving it.
+func markResolved(root, filename string) error {
+	m, err := loadMergeState(root)
+	if err != nil || m == nil {
+		return err
+	}
+	kept := m.Conflicts[:0]
+	for _, f := range m.Conflicts {
+		if f != filename {
+			kept = append(kept, f)
+		}
+	}
+	m.Conflicts = kept
+	return m.save(root)
+}
+
+// mergeContinueCmd commits a resolved merge with its recorded message.
+func mergeContinueCmd(rp *repo) (string, error) {
+	root, err := rp.open()
+	if err != nil { return "", err }
+	m, err := loadMergeState(root)
+	if err != nil { return "", err }
+	if m == nil { return "", ErrNoMerge }
+	return commitCmd(rp, m.Message)
+}
+
+// mergeAbortCmd puts back the pre-merge working copies of every path the merge
+// touched, clears the auto-staged changes, and forgets the merge.
+func mergeAbortCmd(rp *repo) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+	m, err := loadMergeState(root)
+	if err != nil { return err }
+	if m == nil { return ErrNoMerge }
+
+	j, err := beginJournal(rp, "merge --abort")
+	if err != nil { return err }
+	for f, bid := range m.Orig {
+		if err := j.touch(rp, f, bid); err != nil { return err }
+	}
+
+	// merge refuses to start with staged changes, so the pre-merge index was empty
+	if err := j.setIndex(rp, newIndex()); err != nil { return err }
+	j.Merge[afterSide] = nil
+	return j.run(rp)
+}
@@ -0,0 +1,129 @@
+package gitlet
+
+import "strings"
+
+// lineEdit is one step of an edit script turning a into b.
+// Op is '=' (a[A] == b[B]), '-' (delete a[A]) or '+' (insert b[B]).
+type lineEdit struct {
+	Op   byte
+	A, B int
+}
+
+// splitLines splits text into lines that keep their trailing "\n", so a
+// missing newline at end of file shows up as a difference.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script from a to b using Myers' O(ND)
+// algorithm. Common prefix and suffix lines are matched up front so the
+// search only runs over the changed middle.
+func diffLines(a, b []string) []lineEdit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var edits []lineEdit
+	for i := 0; i < pre; i++ {
+		edits = append(edits, lineEdit{Op: '=', A: i, B: i})
+	}
+	for _, e := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		e.A += pre
+		e.B += pre
+		edits = append(edits, e)
+	}
+	for i := suf; i > 0; i-- {
+		edits = append(edits, lineEdit{Op: '=', A: len(a) - i, B: len(b) - i})
+	}
+	return edits
+}
+
+func myers(a, b []string) []lineEdit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	off := max
+	v := make([]int, 2*max+2) // v[off+k] = furthest x reached on diagonal k
+
+	// trace[d][k+d] is the furthest x on diagonal k after d edits.
+	var trace [][]int
+	snapshot := func(d int) []int {
+		return append([]int(nil), v[off-d:off+d+1]...)
+	}
+
+search:
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1] // step down: insertion
+			} else {
+				x = v[off+k-1] + 1 // step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, snapshot(d))
+				break search
+			}
+		}
+		trace = append(trace, snapshot(d))
+	}
+
+	// Walk the trace backwards from (n, m), emitting edits in reverse.
+	var rev []lineEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			rev = append(rev, lineEdit{Op: '=', A: x - 1, B: y - 1})
+			x--
+			y--
+		}
+		if x == prevX {
+			rev = append(rev, lineEdit{Op: '+', A: x, B: y - 1})
+		} else {
+			rev = append(rev, lineEdit{Op: '-', A: x - 1, B: y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		rev = append(rev, lineEdit{Op: '=', A: x - 1, B: y - 1})
+		x--
+		y--
+	}
+
+	edits := make([]lineEdit, len(rev))
+	for i, e := range rev {
+		edits[len(rev)-1-i] = e
+	}
+	return edits
+}
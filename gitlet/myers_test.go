@@ -0,0 +1,150 @@
+package gitlet
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\nb\n", []string{"a\n", "\n", "b\n"}},
+	}
+	for _, tt := range tests {
+		if got := splitLines([]byte(tt.in)); !slices.Equal(got, tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// checkEdits fails unless edits is a valid script turning a into b with
+// exactly d insertions and deletions.
+func checkEdits(t *testing.T, a, b []string, edits []lineEdit, d int) {
+	t.Helper()
+	i, j, changes := 0, 0, 0
+	for _, e := range edits {
+		switch e.Op {
+		case '=':
+			if e.A != i || e.B != j || a[i] != b[j] {
+				t.Fatalf("bad match %+v at a[%d], b[%d]", e, i, j)
+			}
+			i, j = i+1, j+1
+		case '-':
+			if e.A != i {
+				t.Fatalf("bad delete %+v at a[%d]", e, i)
+			}
+			i, changes = i+1, changes+1
+		case '+':
+			if e.B != j {
+				t.Fatalf("bad insert %+v at b[%d]", e, j)
+			}
+			j, changes = j+1, changes+1
+		}
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("script ends at a[%d], b[%d] of %d, %d lines", i, j, len(a), len(b))
+	}
+	if changes != d {
+		t.Fatalf("script has %d changes, want %d", changes, d)
+	}
+}
+
+// editDistance is the insert/delete distance between a and b, by the
+// textbook longest common subsequence table.
+func editDistance(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"", "a\nb\n", 2},
+		{"a\nb\n", "", 2},
+		{"a\nb\nc\n", "a\nb\nc\n", 0},
+		{"a\nb\nc\n", "a\nX\nc\n", 2},
+		{"a\nb\nc\nd\n", "b\nc\nd\ne\n", 2},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 5}, // the example from Myers' paper
+		{"a\nb", "a\nb\n", 2},
+	}
+	for _, tt := range tests {
+		a, b := splitLines([]byte(tt.a)), splitLines([]byte(tt.b))
+		checkEdits(t, a, b, diffLines(a, b), tt.d)
+	}
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a'+rng.Intn(4))) + "\n"
+		}
+		return lines
+	}
+	for n := 0; n < 500; n++ {
+		a, b := random(), random()
+		checkEdits(t, a, b, diffLines(a, b), editDistance(a, b))
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		ctx  int
+		want string
+	}{
+		{"no change", "a\nb\n", "a\nb\n", 3, ""},
+		{"separate hunks", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "a\nB\nc\nd\ne\nf\ng\nh\nI\nj\n", 1,
+			"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8,3 +8,3 @@\n h\n-i\n+I\n j\n"},
+		{"overlapping context merges", "a\nb\nc\n", "a\nX\nc\nY\n", 1,
+			"@@ -1,3 +1,4 @@\n a\n-b\n+X\n c\n+Y\n"},
+		{"into an empty file", "", "x\ny\n", 3, "@@ -0,0 +1,2 @@\n+x\n+y\n"},
+		{"missing newline", "a\nb", "a\nb\n", 3,
+			"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedHunks(splitLines([]byte(tt.a)), splitLines([]byte(tt.b)), tt.ctx)
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedFileDiff(t *testing.T) {
+	got := unifiedFileDiff("f.txt", nil, []byte("x\n"), true, false)
+	want := "diff --git a/f.txt b/f.txt\nnew file\n--- /dev/null\n+++ b/f.txt\n@@ -0,0 +1 @@\n+x\n"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	got = unifiedFileDiff("bin", []byte("a\x00"), []byte("b\x00"), false, false)
+	if !strings.HasSuffix(got, "Binary files a/bin and b/bin differ\n") {
+		t.Fatalf("binary diff:\n%s", got)
+	}
+}
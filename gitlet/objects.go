@@ -0,0 +1,156 @@
+package gitlet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// objectTypeTag maps an objects/ directory to the type tag hashed in front of
+// its content ("blob\n", "commit\n", ...).
+var objectTypeTag = map[string]string{
+	"blobs":   "blob",
+	"commits": "commit",
+	"trees":   "tree",
+	"tags":    "tag",
+}
+
+// hashObject computes the id an object of kind with these stored bytes must have.
+func hashObject(kind string, data []byte) string {
+	h := sha1.New()
+	h.Write([]byte(objectTypeTag[kind] + "\n"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CorruptObjectError is returned by reads whose content does not hash to the
+// id it was stored under.
+type CorruptObjectError struct {
+	Kind string // blob, commit, tree or tag
+	ID   string
+	Path string
+	Got  string // what the content actually hashes to
+}
+
+func (e *CorruptObjectError) Error() string {
+	return fmt.Sprintf("Object %s %s is corrupt: %s hashes to %s.", e.Kind, e.ID, e.Path, e.Got)
+}
+
+// verifyReads reports whether reads rehash objects, by core.verifyObjects:
+//
+//	checkout  verify what is written to the working tree (the default)
+//	always    verify every read
+//	never     trust the store
+func (rp *repo) verifyReads(worktree bool) bool {
+	switch rp.verify {
+	case "always", "true":
+		return true
+	case "never", "false":
+		return false
+	}
+	return worktree
+}
+
+// Loose objects are stored compressed:
+//
+//	"\x00glz" <type> " " <length> "\n" <zlib stream of the content>
+//
+// Ids are always computed over the uncompressed content. Objects written
+// before compression hold their content raw; the leading NUL tells the two
+// apart, since no commit, tree or tag starts with one. A raw blob that does
+// start with the magic fails to parse as compressed, and is recognized by
+// hashing to its id as it stands (see decodeObject).
+const looseMagic = "\x00glz"
+
+// encodeObject wraps content in the compressed loose-object format.
+func encodeObject(kind string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(looseMagic)
+	fmt.Fprintf(&buf, "%s %d\n", objectTypeTag[kind], len(data))
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeObject returns the content of stored object id of kind, compressed
+// or legacy raw.
+func decodeObject(kind, id string, raw []byte) ([]byte, error) {
+	if !bytes.HasPrefix(raw, []byte(looseMagic)) {
+		return raw, nil
+	}
+	data, err := decompressObject(kind, raw)
+	if err != nil && hashObject(kind, raw) == id {
+		return raw, nil // a legacy blob that merely starts with the magic
+	}
+	return data, err
+}
+
+// decompressObject parses the compressed loose-object format.
+func decompressObject(kind string, raw []byte) ([]byte, error) {
+	if !bytes.HasPrefix(raw, []byte(looseMagic)) {
+		return nil, errors.New("bad object header")
+	}
+	header, body, ok := bytes.Cut(raw[len(looseMagic):], []byte("\n"))
+	if !ok {
+		return nil, errors.New("bad object header")
+	}
+	typ, size, _ := strings.Cut(string(header), " ")
+	n, err := strconv.Atoi(size)
+	if err != nil || typ != objectTypeTag[kind] {
+		return nil, fmt.Errorf("bad object header %q", header)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != n {
+		return nil, fmt.Errorf("object is %d bytes, header says %d", len(data), n)
+	}
+	return data, nil
+}
+
+// hasObject reports whether object id is in the repository's store.
+func hasObject(rp *repo, kind, id string) bool {
+	return rp.store.Has(kind, id)
+}
+
+// readObject returns the content of object id from the repository's store,
+// rehashing it first when verifyReads says so.
+func readObject(rp *repo, kind, id string) ([]byte, error) {
+	return readObjectVerified(rp, kind, id, rp.verifyReads(false))
+}
+
+// readObjectVerified is readObject that rehashes the content when verify is
+// set.
+func readObjectVerified(rp *repo, kind, id string, verify bool) ([]byte, error) {
+	b, err := rp.store.Get(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if verify {
+		if got := hashObject(kind, b); got != id {
+			where := "the object store"
+			if fs, ok := rp.store.(*fsStore); ok {
+				where = fs.where(kind, id)
+			}
+			return nil, &CorruptObjectError{Kind: objectTypeTag[kind], ID: id, Path: where, Got: got}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,88 @@
+package gitlet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeObject(t *testing.T) {
+	content := []byte("hello\n")
+	enc, err := encodeObject("blobs", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte(looseMagic + "blob 3\nnot zlib")
+	tests := []struct {
+		name    string
+		id      string
+		raw     []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"compressed", hashObject("blobs", content), enc, content, false},
+		{"legacy raw", hashObject("blobs", content), content, content, false},
+		{"legacy raw starting with the magic", hashObject("blobs", legacy), legacy, legacy, false},
+		{"damaged compressed", hashObject("blobs", content), enc[:len(enc)-3], nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeObject("blobs", tt.id, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyObjects(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "a.txt", "good\n")
+	if err := r.Add("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Commit("add a"); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := encodeObject("blobs", []byte("evil\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, path := objectPath(filepath.Join(dir, ".gitlet"), "blobs", blobID([]byte("good\n")))
+	if err := os.WriteFile(path, enc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	removeFile(t, dir, "a.txt")
+
+	// checkout (the default) verifies what goes into the working tree only
+	var corrupt *CorruptObjectError
+	if err := r.CheckoutFile("", "a.txt"); !errors.As(err, &corrupt) {
+		t.Fatalf("checkout: %v, want a CorruptObjectError", err)
+	}
+	if _, err := r.DiffCommits("HEAD~1", "HEAD"); err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	// the setting is read by every operation of the same Repository
+	writeFile(t, dir, ".gitlet/config", "[core]\nverifyObjects = always\n")
+	if _, err := r.DiffCommits("HEAD~1", "HEAD"); !errors.As(err, &corrupt) {
+		t.Fatalf("diff with always: %v, want a CorruptObjectError", err)
+	}
+	writeFile(t, dir, ".gitlet/config", "[core]\nverifyObjects = never\n")
+	if err := r.CheckoutFile("", "a.txt"); err != nil {
+		t.Fatalf("checkout with never: %v", err)
+	}
+	if got := readFile(t, dir, "a.txt"); got != "evil\n" {
+		t.Fatalf("a.txt = %q", got)
+	}
+}
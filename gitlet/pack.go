@@ -0,0 +1,624 @@
+package gitlet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// A pack holds many objects in one file, objects/pack/pack-<sum>.pack:
+//
+//	"GPAK" version:u32 count:u32
+//	entries: type:u8 enc:u8 [base id:20 if enc == 1] size:uvarint zlib-data[size]
+//	sha1 of everything above
+//
+// enc 0 stores the content itself, enc 1 a delta against another object of
+// the same pack (see deltaEncode). The matching pack-<sum>.idx lists every
+// entry sorted by id, so a lookup is a binary search:
+//
+//	"GIDX" version:u32 count:u32
+//	records: id:20 type:u8 offset:u64 length:u64
+//
+// The idx is written after the pack, so a pack without one is ignored.
+const (
+	packMagic    = "GPAK"
+	idxMagic     = "GIDX"
+	packVersion  = 1
+	idxRecordLen = 20 + 1 + 8 + 8
+
+	packEncFull  = 0
+	packEncDelta = 1
+
+	// deltaWindow is how many preceding candidates repack tries as a delta
+	// base; maxDeltaDepth bounds the chain a read has to follow.
+	deltaWindow   = 10
+	maxDeltaDepth = 10
+)
+
+// packTypes maps a pack entry's type byte to its objects/ directory.
+var packTypes = []string{"", "blobs", "commits", "trees", "tags"}
+
+func packType(kind string) byte {
+	for i, k := range packTypes {
+		if k == kind {
+			return byte(i)
+		}
+	}
+	return 0
+}
+
+// CorruptPackError reports a pack or pack index that cannot be read.
+type CorruptPackError struct {
+	Path   string // the .pack or .idx file
+	Reason string
+}
+
+func (e *CorruptPackError) Error() string {
+	return fmt.Sprintf("Pack %s is corrupt: %s.", e.Path, e.Reason)
+}
+
+func packDir(root string) string { return filepath.Join(root, "objects", "pack") }
+
+// pack is one loaded index plus the path of its pack file.
+type pack struct {
+	path string // .pack file
+	idx  []byte // records only
+	n    int
+	mod  int64 // pack mtime, unix seconds
+}
+
+func (p *pack) record(i int) []byte { return p.idx[i*idxRecordLen : (i+1)*idxRecordLen] }
+func (p *pack) id(i int) string     { return hex.EncodeToString(p.record(i)[:20]) }
+func (p *pack) kind(i int) string   { return packTypes[p.record(i)[20]] }
+func (p *pack) offset(i int) int64  { return int64(binary.BigEndian.Uint64(p.record(i)[21:29])) }
+func (p *pack) length(i int) int64  { return int64(binary.BigEndian.Uint64(p.record(i)[29:37])) }
+
+// search returns the first record whose id is >= id (hex, possibly a prefix).
+func (p *pack) search(id string) int {
+	return sort.Search(p.n, func(i int) bool { return p.id(i) >= id })
+}
+
+func (p *pack) find(id string) (int, bool) {
+	i := p.search(id)
+	return i, i < p.n && p.id(i) == id
+}
+
+// packSet is the packs an fsStore has open, with the index files they were
+// loaded from. Another process may repack at any time, so every lookup lists
+// objects/pack again and reloads when the set of indexes changed.
+type packSet struct {
+	names []string // pack-*.idx paths, sorted
+	packs []*pack
+}
+
+func (s *fsStore) loadPacks() ([]*pack, error) {
+	names, err := filepath.Glob(filepath.Join(packDir(s.root), "pack-*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.packs != nil && slices.Equal(s.packs.names, names) {
+		return s.packs.packs, nil
+	}
+	var ps []*pack
+	for _, name := range names {
+		p, err := openPack(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // removed by a repack since the listing
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+	s.packs = &packSet{names: names, packs: ps}
+	return ps, nil
+}
+
+// forgetPacks drops the cached pack list, so the next lookup reopens them.
+func (s *fsStore) forgetPacks() {
+	s.mu.Lock()
+	s.packs = nil
+	s.mu.Unlock()
+}
+
+func openPack(idxPath string) (*pack, error) {
+	b, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 12 || string(b[:4]) != idxMagic || binary.BigEndian.Uint32(b[4:8]) != packVersion {
+		return nil, &CorruptPackError{idxPath, "bad header"}
+	}
+	n := int(binary.BigEndian.Uint32(b[8:12]))
+	if len(b) != 12+n*idxRecordLen {
+		return nil, &CorruptPackError{idxPath, fmt.Sprintf("%d bytes for %d records", len(b), n)}
+	}
+	packPath := strings.TrimSuffix(idxPath, ".idx") + ".pack"
+	st, err := os.Stat(packPath)
+	if err != nil {
+		return nil, err
+	}
+	p := &pack{path: packPath, idx: b[12:], n: n, mod: st.ModTime().Unix()}
+	// every record is checked here, so lookups can trust the index
+	end := uint64(max(st.Size()-sha1.Size, 0))
+	for i := 0; i < n; i++ {
+		rec := p.record(i)
+		if t := rec[20]; t == 0 || int(t) >= len(packTypes) {
+			return nil, &CorruptPackError{idxPath, fmt.Sprintf("unknown type %d for %s", t, p.id(i))}
+		}
+		off, length := binary.BigEndian.Uint64(rec[21:29]), binary.BigEndian.Uint64(rec[29:37])
+		if off < 12 || off > end || length > end-off {
+			return nil, &CorruptPackError{idxPath, fmt.Sprintf("entry for %s lies outside %s", p.id(i), filepath.Base(packPath))}
+		}
+	}
+	return p, nil
+}
+
+// findPacked returns the content of object id from whichever pack holds it.
+// A pack deleted between listing and reading, by a repack in another
+// process, sends it back to list the packs again.
+func (s *fsStore) findPacked(kind, id string) (data []byte, path string, ok bool, err error) {
+	data, path, ok, err = s.findPackedOnce(kind, id)
+	if errors.Is(err, os.ErrNotExist) {
+		s.forgetPacks()
+		return s.findPackedOnce(kind, id)
+	}
+	return data, path, ok, err
+}
+
+func (s *fsStore) findPackedOnce(kind, id string) ([]byte, string, bool, error) {
+	ps, err := s.loadPacks()
+	if err != nil {
+		return nil, "", false, err
+	}
+	for _, p := range ps {
+		if i, found := p.find(id); found && p.kind(i) == kind {
+			data, err := p.read(i, 0)
+			return data, p.path, true, err
+		}
+	}
+	return nil, "", false, nil
+}
+
+// packedIDs returns the ids of packed objects of kind starting with prefix.
+func (s *fsStore) packedIDs(kind, prefix string) ([]string, error) {
+	ps, err := s.loadPacks()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, p := range ps {
+		for i := p.search(prefix); i < p.n; i++ {
+			id := p.id(i)
+			if !strings.HasPrefix(id, prefix) {
+				break
+			}
+			if p.kind(i) == kind {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// read returns the content of entry i, applying deltas down to their base.
+func (p *pack) read(i, depth int) ([]byte, error) {
+	if depth > maxDeltaDepth {
+		return nil, fmt.Errorf("delta chain too long in %s", p.path)
+	}
+	f, err := os.Open(p.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entry := make([]byte, p.length(i))
+	if _, err := f.ReadAt(entry, p.offset(i)); err != nil {
+		return nil, err
+	}
+	bad := func(reason string) error {
+		return &CorruptPackError{p.path, fmt.Sprintf("entry for %s %s", p.id(i), reason)}
+	}
+	if len(entry) < 2 {
+		return nil, bad("is truncated")
+	}
+	typ, enc, rest := entry[0], entry[1], entry[2:]
+	if typ != p.record(i)[20] {
+		return nil, bad(fmt.Sprintf("has type %d, the index says %d", typ, p.record(i)[20]))
+	}
+	var base string
+	switch enc {
+	case packEncFull:
+	case packEncDelta:
+		if len(rest) < 20 {
+			return nil, bad("is truncated")
+		}
+		base, rest = hex.EncodeToString(rest[:20]), rest[20:]
+	default:
+		return nil, bad(fmt.Sprintf("has unknown encoding %d", enc))
+	}
+	size, k := binary.Uvarint(rest)
+	if k <= 0 || uint64(len(rest)-k) < size {
+		return nil, bad("is truncated")
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(rest[k : k+int(size)]))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	if enc == packEncFull {
+		return data, nil
+	}
+	j, ok := p.find(base)
+	if !ok {
+		return nil, fmt.Errorf("delta base %s of %s is not in %s", base, p.id(i), p.path)
+	}
+	baseData, err := p.read(j, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return deltaApply(baseData, data)
+}
+
+// A delta is uvarint(len(base)) uvarint(len(target)) followed by
+// instructions: 0 offset:uvarint n:uvarint copies n bytes of base, 1
+// n:uvarint bytes[n] inserts literal bytes.
+const deltaBlock = 16
+
+// deltaEncode describes target in terms of base, matching 16-byte blocks of
+// base and extending each match as far as it goes.
+func deltaEncode(base, target []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(base)))
+	out = binary.AppendUvarint(out, uint64(len(target)))
+	index := map[string]int{}
+	for i := 0; i+deltaBlock <= len(base); i += deltaBlock {
+		if _, ok := index[string(base[i:i+deltaBlock])]; !ok {
+			index[string(base[i:i+deltaBlock])] = i
+		}
+	}
+	var lit []byte
+	flush := func() {
+		if len(lit) > 0 {
+			out = append(out, 1)
+			out = binary.AppendUvarint(out, uint64(len(lit)))
+			out = append(out, lit...)
+			lit = lit[:0]
+		}
+	}
+	for i := 0; i < len(target); {
+		if i+deltaBlock <= len(target) {
+			if off, ok := index[string(target[i:i+deltaBlock])]; ok {
+				// grow the match backwards over pending literal bytes
+				for len(lit) > 0 && off > 0 && base[off-1] == lit[len(lit)-1] {
+					off--
+					i--
+					lit = lit[:len(lit)-1]
+				}
+				n := 0
+				for off+n < len(base) && i+n < len(target) && base[off+n] == target[i+n] {
+					n++
+				}
+				flush()
+				out = append(out, 0)
+				out = binary.AppendUvarint(out, uint64(off))
+				out = binary.AppendUvarint(out, uint64(n))
+				i += n
+				continue
+			}
+		}
+		lit = append(lit, target[i])
+		i++
+	}
+	flush()
+	return out
+}
+
+var errBadDelta = errors.New("bad delta")
+
+func deltaApply(base, delta []byte) ([]byte, error) {
+	next := func() (int, error) {
+		v, k := binary.Uvarint(delta)
+		if k <= 0 || v > math.MaxInt32 {
+			return 0, errBadDelta
+		}
+		delta = delta[k:]
+		return int(v), nil
+	}
+	baseLen, err := next()
+	if err != nil || baseLen != len(base) {
+		return nil, errBadDelta
+	}
+	targetLen, err := next()
+	if err != nil {
+		return nil, err
+	}
+	// a corrupt length must not size the buffer; out only grows as the
+	// instructions fill it, and never past targetLen
+	out := make([]byte, 0, min(targetLen, len(base)+len(delta)))
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch op {
+		case 0:
+			off, err := next()
+			if err != nil {
+				return nil, err
+			}
+			n, err := next()
+			if err != nil || off > len(base) || n > len(base)-off || n > targetLen-len(out) {
+				return nil, errBadDelta
+			}
+			out = append(out, base[off:off+n]...)
+		case 1:
+			n, err := next()
+			if err != nil || n > len(delta) || n > targetLen-len(out) {
+				return nil, errBadDelta
+			}
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+		default:
+			return nil, errBadDelta
+		}
+	}
+	if len(out) != targetLen {
+		return nil, errBadDelta
+	}
+	return out, nil
+}
+
+// packObject is one object on its way into a new pack.
+type packObject struct {
+	kind, id string
+	data     []byte
+	base     string // delta base id, "" for a full entry
+	delta    []byte
+	depth    int
+}
+
+// chooseDeltas picks, for each blob, the smallest delta against one of the
+// deltaWindow blobs before it when ordered by file name and size, so
+// versions of the same file end up next to each other. A delta is only kept
+// when it is under half the blob's size.
+func chooseDeltas(objs []*packObject, names map[string]string) {
+	var blobs []*packObject
+	for _, o := range objs {
+		if o.kind == "blobs" {
+			blobs = append(blobs, o)
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool {
+		a, b := blobs[i], blobs[j]
+		if names[a.id] != names[b.id] {
+			return names[a.id] < names[b.id]
+		}
+		if len(a.data) != len(b.data) {
+			return len(a.data) > len(b.data)
+		}
+		return a.id < b.id
+	})
+	for i, o := range blobs {
+		for j := i - 1; j >= 0 && j >= i-deltaWindow; j-- {
+			b := blobs[j]
+			if b.depth >= maxDeltaDepth || len(b.data) == 0 {
+				continue
+			}
+			d := deltaEncode(b.data, o.data)
+			if len(d) < len(o.data)/2 && (o.delta == nil || len(d) < len(o.delta)) {
+				o.base, o.delta, o.depth = b.id, d, b.depth+1
+			}
+		}
+	}
+}
+
+// writePack stores objs as a new pack and index and returns the pack path.
+func writePack(root string, objs []*packObject) (string, error) {
+	sort.Slice(objs, func(i, j int) bool { return objs[i].id < objs[j].id })
+
+	var buf bytes.Buffer
+	buf.WriteString(packMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(packVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(objs)))
+
+	var idx bytes.Buffer
+	idx.WriteString(idxMagic)
+	binary.Write(&idx, binary.BigEndian, uint32(packVersion))
+	binary.Write(&idx, binary.BigEndian, uint32(len(objs)))
+
+	for _, o := range objs {
+		start := buf.Len()
+		payload := o.data
+		if o.base != "" {
+			buf.Write([]byte{packType(o.kind), packEncDelta})
+			raw, _ := hex.DecodeString(o.base)
+			buf.Write(raw)
+			payload = o.delta
+		} else {
+			buf.Write([]byte{packType(o.kind), packEncFull})
+		}
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(payload); err != nil {
+			return "", err
+		}
+		if err := zw.Close(); err != nil {
+			return "", err
+		}
+		buf.Write(binary.AppendUvarint(nil, uint64(z.Len())))
+		buf.Write(z.Bytes())
+
+		raw, _ := hex.DecodeString(o.id)
+		idx.Write(raw)
+		idx.WriteByte(packType(o.kind))
+		binary.Write(&idx, binary.BigEndian, uint64(start))
+		binary.Write(&idx, binary.BigEndian, uint64(buf.Len()-start))
+	}
+	sum := sha1.Sum(buf.Bytes())
+	buf.Write(sum[:])
+
+	name := filepath.Join(packDir(root), "pack-"+hex.EncodeToString(sum[:]))
+	if err := writeAtomic(name+".pack", buf.Bytes()); err != nil {
+		return "", err
+	}
+	if err := writeAtomic(name+".idx", idx.Bytes()); err != nil {
+		return "", err
+	}
+	return name + ".pack", nil
+}
+
+// checkPack reads every deltified object back from the pack just written
+// and compares it with what went in, so a bad delta is caught while the
+// loose files and old packs still hold the object.
+func checkPack(path string, objs []*packObject) error {
+	p, err := openPack(strings.TrimSuffix(path, ".pack") + ".idx")
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		if o.base == "" {
+			continue
+		}
+		i, ok := p.find(o.id)
+		if !ok {
+			return fmt.Errorf("%s %s is missing from %s", objectTypeTag[o.kind], o.id, path)
+		}
+		data, err := p.read(i, 0)
+		if err != nil {
+			return fmt.Errorf("%s %s does not read back from %s: %v", objectTypeTag[o.kind], o.id, path, err)
+		}
+		if !bytes.Equal(data, o.data) {
+			return fmt.Errorf("%s %s does not read back from %s", objectTypeTag[o.kind], o.id, path)
+		}
+	}
+	return nil
+}
+
+// repack rewrites every packed object (and, with loose, every loose object)
+// except those in drop into a single new pack, then deletes the old packs
+// and the loose files it absorbed. It returns how many objects it packed.
+func repack(rp *repo, drop map[string]bool, loose bool) (int, error) {
+	root := rp.root
+	fs := rp.store.(*fsStore) // callers have checked with fileStore
+	old, err := fs.loadPacks()
+	if err != nil {
+		return 0, err
+	}
+	var objs []*packObject
+	seen := map[string]bool{}
+	var looseFiles []string
+	add := func(kind, id string) error {
+		if seen[id] || drop[id] {
+			return nil
+		}
+		seen[id] = true
+		data, err := fs.Get(kind, id)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, &packObject{kind: kind, id: id, data: data})
+		return nil
+	}
+	for _, kind := range objectKinds {
+		if loose {
+			los, err := listObjects(root, kind)
+			if err != nil {
+				return 0, err
+			}
+			for _, o := range los {
+				if err := add(kind, o.ID); err != nil {
+					return 0, err
+				}
+				if !drop[o.ID] {
+					_, path := objectPath(root, kind, o.ID)
+					looseFiles = append(looseFiles, path)
+				}
+			}
+		}
+		ids, err := fs.packedIDs(kind, "")
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range ids {
+			if err := add(kind, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// file names of blobs, to put versions of the same file side by side
+	names := map[string]string{}
+	for _, o := range objs {
+		if o.kind != "trees" {
+			continue
+		}
+		entries, err := readTree(rp, o.id)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range entries {
+			if _, ok := names[e.ID]; !ok && e.Kind == "blob" {
+				names[e.ID] = e.Name
+			}
+		}
+	}
+	chooseDeltas(objs, names)
+
+	var newPath string
+	if len(objs) > 0 {
+		if newPath, err = writePack(root, objs); err != nil {
+			return 0, err
+		}
+		if err := checkPack(newPath, objs); err != nil {
+			os.Remove(strings.TrimSuffix(newPath, ".pack") + ".idx")
+			os.Remove(newPath)
+			return 0, err
+		}
+	}
+	for _, p := range old {
+		if p.path == newPath {
+			continue
+		}
+		if err := os.Remove(strings.TrimSuffix(p.path, ".pack") + ".idx"); err != nil {
+			return 0, err
+		}
+		if err := os.Remove(p.path); err != nil {
+			return 0, err
+		}
+	}
+	fs.forgetPacks()
+	for _, path := range looseFiles {
+		if err := os.Remove(path); err != nil {
+			return 0, err
+		}
+		os.Remove(filepath.Dir(path)) // only succeeds once the shard is empty
+	}
+	return len(objs), nil
+}
+
+// repackCmd moves every object into one pack, with similar blobs stored as
+// deltas, and returns how many objects it packed.
+func repackCmd(rp *repo) (int, error) {
+	root, err := rp.open()
+	if err != nil { return 0, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return 0, err }
+	defer unlock()
+	if _, err := rp.fileStore("repack"); err != nil { return 0, err }
+	return repack(rp, nil, true)
+}
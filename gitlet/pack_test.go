@@ -0,0 +1,203 @@
+package gitlet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeltaRoundTrip(t *testing.T) {
+	lines := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteString(strings.Repeat("x", i%7) + " line\n")
+		}
+		return sb.String()
+	}
+	big := lines(5000)
+	tests := []struct {
+		name         string
+		base, target string
+	}{
+		{"identical", big, big},
+		{"empty target", big, ""},
+		{"no common block", "short", "other"},
+		{"appended", big, big + "tail\n"},
+		{"prepended", big, "head\n" + big},
+		{"edited middle", big, big[:len(big)/2] + "changed\n" + big[len(big)/2:]},
+		{"three copies", big, big + big + big},
+		{"many copies of a small base", strings.Repeat("0123456789abcdef", 2), strings.Repeat("0123456789abcdef", 5000)},
+		{"repeats with literals", big, big + "x" + big + "y" + big},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := deltaEncode([]byte(tt.base), []byte(tt.target))
+			got, err := deltaApply([]byte(tt.base), d)
+			if err != nil {
+				t.Fatalf("deltaApply: %v", err)
+			}
+			if !bytes.Equal(got, []byte(tt.target)) {
+				t.Fatalf("round trip gave %d bytes, want %d", len(got), len(tt.target))
+			}
+		})
+	}
+}
+
+func TestDeltaApplyRejectsBadDeltas(t *testing.T) {
+	base := []byte(strings.Repeat("0123456789abcdef", 4))
+	good := deltaEncode(base, append(base, base...))
+	tests := []struct {
+		name  string
+		delta []byte
+	}{
+		{"empty", nil},
+		{"wrong base length", append([]byte{1}, good[1:]...)},
+		{"truncated", good[:len(good)-1]},
+		{"copy past the base", []byte{64, 10, 0, 60, 10}},
+		{"copy overflowing the target", []byte{64, 10, 0, 0, 20}},
+		{"literal past the delta", []byte{64, 3, 1, 3, 'a'}},
+		{"short of the target", []byte{64, 10, 0, 0, 5}},
+		{"unknown op", []byte{64, 0, 7}},
+		{"huge target", []byte{64, 0xff, 0xff, 0xff, 0xff, 0x0f}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := deltaApply(base, tt.delta); err == nil {
+				t.Fatal("deltaApply accepted a bad delta")
+			}
+		})
+	}
+}
+
+func TestRepackReadsBackRepeatedBlobs(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := strings.Repeat("some line of text\n", 2000)
+	files := map[string]string{"a.txt": a, "b.txt": a + a + a, "c.txt": a + "end\n"}
+	for name, data := range files {
+		writeFile(t, dir, name, data)
+		if err := r.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.Commit("three similar files"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Repack(); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		removeFile(t, dir, name)
+		if err := r.CheckoutFile("", name); err != nil {
+			t.Fatalf("checkout -- %s: %v", name, err)
+		}
+		if got := readFile(t, dir, name); got != data {
+			t.Fatalf("%s came back with %d bytes, want %d", name, len(got), len(data))
+		}
+	}
+	problems, err := r.Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		if p.Code != "dangling" {
+			t.Errorf("fsck: %s", p)
+		}
+	}
+}
+
+func TestCorruptPackIsReportedNotPanicked(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "a.txt", "packed\n")
+	if err := r.Add("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Commit("one file"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Repack(); err != nil {
+		t.Fatal(err)
+	}
+	idxs, err := filepath.Glob(filepath.Join(packDir(filepath.Join(dir, ".gitlet")), "pack-*.idx"))
+	if err != nil || len(idxs) != 1 {
+		t.Fatalf("packs: %v, %v", idxs, err)
+	}
+	idxPath := idxs[0]
+	packPath := strings.TrimSuffix(idxPath, ".idx") + ".pack"
+	p, err := openPack(idxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, ok := p.find(hashObject("blobs", []byte("packed\n")))
+	if !ok {
+		t.Fatal("blob is not in the pack")
+	}
+	rec, entry := 12+i*idxRecordLen, int(p.offset(i))
+	idx0, pack0 := readBytes(t, idxPath), readBytes(t, packPath)
+	removeFile(t, dir, "a.txt")
+
+	tests := []struct {
+		name      string
+		idx, pack func(b []byte)
+	}{
+		{"unknown type in the index", func(b []byte) { b[rec+20] = 9 }, nil},
+		{"zero type in the index", func(b []byte) { b[rec+20] = 0 }, nil},
+		{"entry past the pack", func(b []byte) { binary.BigEndian.PutUint64(b[rec+29:], 1<<62) }, nil},
+		{"entry type differs from the index", nil, func(b []byte) { b[entry] = packType("trees") }},
+		{"unknown entry type", nil, func(b []byte) { b[entry] = 200 }},
+		{"unknown encoding", nil, func(b []byte) { b[entry+1] = 7 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, pack := bytes.Clone(idx0), bytes.Clone(pack0)
+			if tt.idx != nil {
+				tt.idx(idx)
+			}
+			if tt.pack != nil {
+				tt.pack(pack)
+			}
+			writeBytes(t, idxPath, idx)
+			writeBytes(t, packPath, pack)
+			t.Cleanup(func() {
+				writeBytes(t, idxPath, idx0)
+				writeBytes(t, packPath, pack0)
+			})
+			r, err := Open(dir) // a new handle, so the pack is opened again
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = r.CheckoutFile("", "a.txt")
+			var ce *CorruptPackError
+			if !errors.As(err, &ce) {
+				t.Fatalf("checkout -- a.txt: %v, want a *CorruptPackError", err)
+			}
+		})
+	}
+}
+
+func readBytes(t *testing.T, path string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func writeBytes(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
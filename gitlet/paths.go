@@ -0,0 +1,81 @@
+package gitlet
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// normalizePath turns a user-supplied filename into the slash-separated,
+// repo-relative form used as a key in Commit.Files and the Index.
+// Paths that are absolute, climb out with "..", or point into .gitlet are rejected.
+func normalizePath(name string) (string, error) {
+	if name == "" {
+		return "", ErrFileNotFound
+	}
+	if filepath.IsAbs(name) || strings.HasPrefix(filepath.ToSlash(name), "/") {
+		return "", ErrPathOutside
+	}
+	p := path.Clean(filepath.ToSlash(name))
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", ErrPathOutside
+	}
+	if p == ".gitlet" || strings.HasPrefix(p, ".gitlet/") {
+		return "", ErrPathInGitlet
+	}
+	return p, nil
+}
+
+// writeWorkingFile writes a repo-relative file, creating parent directories as needed.
+func writeWorkingFile(cwd, rel string, data []byte) error {
+	abs := filepath.Join(cwd, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(abs, data, 0o644)
+}
+
+// removeWorkingFile deletes a repo-relative file (ignoring a missing one) and then
+// prunes any parent directories the removal left empty, stopping at cwd.
+func removeWorkingFile(cwd, rel string) {
+	// a parent that is a file (not a directory) must not be "pruned"
+	if err := os.Remove(filepath.Join(cwd, filepath.FromSlash(rel))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		// os.Remove refuses non-empty directories, which is exactly the stop condition.
+		if err := os.Remove(filepath.Join(cwd, filepath.FromSlash(dir))); err != nil {
+			return
+		}
+	}
+}
+
+// listWorkingFiles returns every regular file under cwd as a slash-separated,
+// repo-relative path, skipping the .gitlet directory.
+func listWorkingFiles(cwd string) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(cwd, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(cwd, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel == ".gitlet" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(out)
+	return out, err
+}
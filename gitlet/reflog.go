@@ -0,0 +1,209 @@
+package gitlet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// zeroID stands for "no commit" as the old value of a newly created ref.
+const zeroID = "0000000000000000000000000000000000000000"
+
+// Every change to HEAD or a branch appends one line to .gitlet/logs/<ref>
+// (logs/HEAD, logs/refs/heads/<branch>):
+//
+//	<old id> <new id> <Name <email>> <timestamp>\t<reason>
+//
+// Tags are never logged, matching Git.
+type ReflogEntry struct {
+	Old, New     string
+	Identity     string
+	TimestampRFC string
+	Reason       string
+}
+
+func reflogPath(root, ref string) string {
+	return filepath.Join(root, "logs", filepath.FromSlash(ref))
+}
+
+func logsRef(ref string) bool {
+	return ref == "HEAD" || strings.HasPrefix(ref, "refs/heads/")
+}
+
+// readRef returns the id stored in a ref file ("refs/heads/x", "refs/tags/x",
+// or "HEAD" for the current commit), or "" when it does not exist.
+func readRef(root, ref string) string {
+	if ref == "HEAD" {
+		id, _ := headCommitID(root)
+		return id
+	}
+	b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(ref)))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// updateRef points ref at id and records the move in its reflog; all ref
+// writes go through here. ref "HEAD" writes a detached HEAD. Moving the
+// checked-out branch is logged for HEAD as well.
+//
+// old is the value the caller read before deciding on id (zeroID: the ref
+// must not exist yet; "": no check). The ref is locked while it is compared
+// and written, so a ref another process moved meanwhile fails with a
+// *RefMovedError instead of being overwritten.
+func updateRef(root, ref, old, id, reason string) error {
+	path := filepath.Join(root, filepath.FromSlash(ref))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	release, err := acquireLock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	cur := readRef(root, ref)
+	if old != "" {
+		want := old
+		if want == zeroID {
+			want = ""
+		}
+		if cur != want {
+			return &RefMovedError{Ref: ref, Expected: want, Actual: cur}
+		}
+	}
+	if err := writeAtomic(path, []byte(id+"\n")); err != nil {
+		return err
+	}
+	if !logsRef(ref) {
+		return nil
+	}
+	if err := appendReflog(root, ref, cur, id, reason); err != nil {
+		return err
+	}
+	if branch, _ := currentBranch(root); branch != "" && ref == "refs/heads/"+branch {
+		return appendReflog(root, "HEAD", cur, id, reason)
+	}
+	return nil
+}
+
+// setSymbolicHead attaches HEAD to branch; the switch is logged for HEAD
+// once the branch has a commit.
+func setSymbolicHead(root, branch, reason string) error {
+	path := filepath.Join(root, "HEAD")
+	release, err := acquireLock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	old := readRef(root, "HEAD")
+	if err := writeAtomic(path, []byte("ref: refs/heads/"+branch+"\n")); err != nil {
+		return err
+	}
+	if id := readRef(root, "refs/heads/"+branch); id != "" {
+		return appendReflog(root, "HEAD", old, id, reason)
+	}
+	return nil
+}
+
+// deleteRef removes a ref together with its reflog.
+func deleteRef(root, ref string) error {
+	path := filepath.Join(root, filepath.FromSlash(ref))
+	release, err := acquireLock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if err := os.Remove(reflogPath(root, ref)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func appendReflog(root, ref, old, id, reason string) error {
+	if old == "" {
+		old = zeroID
+	}
+	who := resolveIdentity(loadConfig(root), "COMMITTER").String()
+	line := fmt.Sprintf("%s %s %s %s\t%s\n", old, id, who, commitTimestamp(time.Now()),
+		strings.ReplaceAll(reason, "\n", " "))
+
+	path := reflogPath(root, ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readReflog returns ref's log entries, oldest first; a ref that was never
+// logged has none.
+func readReflog(root, ref string) ([]ReflogEntry, error) {
+	f, err := os.Open(reflogPath(root, ref))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var es []ReflogEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		head, reason, _ := strings.Cut(sc.Text(), "\t")
+		fields := strings.SplitN(head, " ", 3)
+		if len(fields) < 3 {
+			continue // torn or foreign line
+		}
+		who, ts := fields[2], ""
+		if i := strings.LastIndexByte(who, ' '); i >= 0 {
+			who, ts = who[:i], who[i+1:]
+		}
+		es = append(es, ReflogEntry{Old: fields[0], New: fields[1], Identity: who, TimestampRFC: ts, Reason: reason})
+	}
+	return es, sc.Err()
+}
+
+// reflogRef maps a user-facing name (HEAD, @, a branch) to the ref it logs.
+func reflogRef(root, name string) (string, error) {
+	if name == "HEAD" || name == "@" {
+		return "HEAD", nil
+	}
+	if _, err := readBranchID(root, name); err != nil {
+		return "", ErrNoSuchBranch
+	}
+	return "refs/heads/" + name, nil
+}
+
+// reflogCmd returns the log of name (a branch, or HEAD when empty), newest
+// first, so entry n is name@{n}.
+func reflogCmd(rp *repo, name string) ([]ReflogEntry, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	if name == "" { name = "HEAD" }
+	ref, err := reflogRef(root, name)
+	if err != nil { return nil, err }
+	es, err := readReflog(root, ref)
+	if err != nil { return nil, err }
+	slices.Reverse(es)
+	return es, nil
+}
@@ -0,0 +1,69 @@
+package gitlet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repo is what one operation works on. A Repository makes a new one for
+// every call, so nothing read from .gitlet (the format, config) outlives the
+// operation; only the object store, with its cached pack list, is shared
+// between the calls of one Repository.
+type repo struct {
+	dir   string // working directory
+	root  string // its .gitlet directory, set by open or find
+	store ObjectStore
+
+	verify string // core.verifyObjects, read by open or find
+}
+
+// open returns "<dir>/.gitlet" if it exists, else ErrNotRepo. A repository
+// in a format this gitlet does not support is refused with an
+// *UnsupportedFormatError, and one with an interrupted checkout, reset or
+// merge pending with an *InterruptedError.
+func (rp *repo) open() (string, error) {
+	root, err := rp.find()
+	if err != nil {
+		return "", err
+	}
+	if err := checkFormat(root); err != nil {
+		return "", err
+	}
+	if err := checkJournal(root); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+// find is open without the format and journal checks, for migrate and
+// recover.
+func (rp *repo) find() (string, error) {
+	root, err := findRoot(rp.dir)
+	if err != nil {
+		return "", err
+	}
+	rp.root = root
+	rp.verify = strings.ToLower(loadConfig(root).get("core.verifyobjects", "checkout"))
+	return root, nil
+}
+
+func findRoot(cwd string) (string, error) {
+	root := filepath.Join(cwd, ".gitlet")
+	st, err := os.Stat(root)
+	if err == nil && st.IsDir() {
+		return root, nil
+	}
+	return "", ErrNotRepo
+}
+
+// fileStore returns the filesystem store, for the commands that maintain
+// its files (gc, repack); other stores are refused.
+func (rp *repo) fileStore(cmd string) (*fsStore, error) {
+	fs, ok := rp.store.(*fsStore)
+	if !ok {
+		return nil, fmt.Errorf("%s only works with objects stored in .gitlet/objects.", cmd)
+	}
+	return fs, nil
+}
@@ -0,0 +1,315 @@
+// Package gitlet is a small Git-like version-control system.
+//
+// A Repository is a working directory with a .gitlet directory in it. Its
+// methods are the gitlet commands: they return values and errors instead of
+// printing, and the gitlet command-line tool is a thin layer over them.
+// Failures the spec names come back as errors whose text is the spec's
+// message ("No changes added to the commit."); errors.Is matches them
+// against the Err sentinels, and the exported error types
+// (*AmbiguousIDError, *LockedError, *InterruptedError, ...) carry details.
+package gitlet
+
+import (
+	"iter"
+	"path/filepath"
+)
+
+// Repository is one gitlet working directory. Between calls it holds only
+// its location and object store: every method rereads .gitlet and takes the
+// repository lock while changing it, so other Repository values and other
+// processes may use the same directory, and one Repository may be used from
+// several goroutines.
+type Repository struct {
+	dir   string
+	store ObjectStore
+}
+
+// Option configures Open and Init.
+type Option func(*Repository)
+
+// WithObjectStore keeps the repository's objects in s instead of under
+// .gitlet/objects; refs, the index and the rest of .gitlet stay on disk.
+// Only the Repository it configures uses s.
+func WithObjectStore(s ObjectStore) Option {
+	return func(r *Repository) { r.store = s }
+}
+
+// NewMemoryStore returns an ObjectStore that keeps objects in memory only.
+func NewMemoryStore() ObjectStore {
+	return newMemStore()
+}
+
+func newRepository(dir string, opts []Option) *Repository {
+	r := &Repository{dir: dir}
+	for _, o := range opts {
+		o(r)
+	}
+	if r.store == nil {
+		r.store = &fsStore{root: filepath.Join(dir, ".gitlet")}
+	}
+	return r
+}
+
+// op starts an operation on the repository.
+func (r *Repository) op() *repo {
+	return &repo{dir: r.dir, store: r.store}
+}
+
+// Open returns the repository whose working directory is dir. Format and
+// interrupted-command checks happen on every operation, so a repository
+// that needs migrate or recover can still be opened to run them.
+func Open(dir string, opts ...Option) (*Repository, error) {
+	dir = filepath.Clean(dir)
+	if _, err := findRoot(dir); err != nil {
+		return nil, err
+	}
+	return newRepository(dir, opts), nil
+}
+
+// Init creates a repository in dir with the initial commit on master.
+func Init(dir string, opts ...Option) (*Repository, error) {
+	r := newRepository(filepath.Clean(dir), opts)
+	if err := initCmd(r.op()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Dir is the repository's working directory.
+func (r *Repository) Dir() string { return r.dir }
+
+// Add stages path (relative to the working directory) as it is now.
+func (r *Repository) Add(path string) error {
+	return addCmd(r.op(), path)
+}
+
+// Rm unstages path, or stages its removal and deletes it if HEAD tracks it.
+func (r *Repository) Rm(path string) error {
+	return rmCmd(r.op(), path)
+}
+
+// Commit records the staged changes (concluding a resolved merge, if one is
+// in progress) and returns the new commit's id.
+func (r *Repository) Commit(msg string) (string, error) {
+	return commitCmd(r.op(), msg)
+}
+
+// ReadCommit returns the commit a revision (id, abbreviation, branch, tag,
+// HEAD~2, ...) names.
+func (r *Repository) ReadCommit(rev string) (LogEntry, error) {
+	rp := r.op()
+	if _, err := rp.open(); err != nil {
+		return LogEntry{}, err
+	}
+	id, err := resolveRevision(rp, rev)
+	if err != nil {
+		return LogEntry{}, err
+	}
+	c, err := readCommit(rp, id)
+	if err != nil {
+		return LogEntry{}, err
+	}
+	return LogEntry{ID: id, Commit: c}, nil
+}
+
+// Log yields the history from rev (HEAD when empty) along first parents,
+// or every commit of a range (a..b, a...b), newest first. An error ends
+// the sequence.
+func (r *Repository) Log(rev string) iter.Seq2[LogEntry, error] {
+	return r.walk(func() (func() (LogEntry, bool, error), error) { return logCmd(r.op(), rev) })
+}
+
+// AllCommits yields every commit in the store, sorted by id.
+func (r *Repository) AllCommits() iter.Seq2[LogEntry, error] {
+	return r.walk(func() (func() (LogEntry, bool, error), error) { return globalLogCmd(r.op()) })
+}
+
+// walk turns a commit producer into an iterator. The producer is started
+// afresh for every range over the result, and the loop body may call other
+// methods.
+func (r *Repository) walk(start func() (func() (LogEntry, bool, error), error)) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		next, err := start()
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+		for {
+			e, ok, err := next()
+			if err != nil {
+				yield(LogEntry{}, err)
+				return
+			}
+			if !ok || !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Find returns the ids of every commit whose message is exactly msg, or
+// ErrNoMatchingCommit when there are none.
+func (r *Repository) Find(msg string) ([]string, error) {
+	return findCmd(r.op(), msg)
+}
+
+// Status reports branches, staged and removed files, unstaged
+// modifications and untracked files.
+func (r *Repository) Status() (*Status, error) {
+	return statusCmd(r.op())
+}
+
+// Checkout switches to a branch, or detaches HEAD at any other revision,
+// rewriting the working tree and clearing the index.
+func (r *Repository) Checkout(name string) (*CheckoutResult, error) {
+	return checkoutCmd(r.op(), name)
+}
+
+// CheckoutFile writes path as it is in rev (HEAD when empty) to the
+// working tree, leaving the index alone.
+func (r *Repository) CheckoutFile(rev, path string) error {
+	if rev == "" {
+		return checkoutHeadFile(r.op(), path)
+	}
+	return checkoutCommitFile(r.op(), rev, path)
+}
+
+// Reset moves the current branch (or a detached HEAD) to rev, rewriting the
+// working tree and clearing the index.
+func (r *Repository) Reset(rev string) (*CheckoutResult, error) {
+	return resetCmd(r.op(), rev)
+}
+
+// Merge merges a branch (or any revision) into HEAD. When it stops for
+// conflicts to be resolved it returns ErrMergeConflict with the result.
+func (r *Repository) Merge(name string) (*MergeResult, error) {
+	return mergeCmd(r.op(), name)
+}
+
+// MergeContinue commits a merge whose conflicts are all resolved and
+// returns the merge commit's id.
+func (r *Repository) MergeContinue() (string, error) {
+	return mergeContinueCmd(r.op())
+}
+
+// MergeAbort puts back the working tree and index as they were before a
+// conflicted merge.
+func (r *Repository) MergeAbort() error {
+	return mergeAbortCmd(r.op())
+}
+
+// Branches returns every branch name, sorted.
+func (r *Repository) Branches() ([]string, error) {
+	root, err := r.op().open()
+	if err != nil {
+		return nil, err
+	}
+	return listBranches(root), nil
+}
+
+// CreateBranch makes a branch at HEAD.
+func (r *Repository) CreateBranch(name string) error {
+	return branchCmd(r.op(), name)
+}
+
+// DeleteBranch removes a branch other than the current one.
+func (r *Repository) DeleteBranch(name string) error {
+	return rmBranchCmd(r.op(), name)
+}
+
+// Tags returns every tag name, sorted.
+func (r *Repository) Tags() ([]string, error) {
+	return tagListCmd(r.op())
+}
+
+// CreateTag tags target (HEAD when empty). A non-empty message makes an
+// annotated tag.
+func (r *Repository) CreateTag(name, target, message string) error {
+	return tagCmd(r.op(), name, target, message != "", message)
+}
+
+// DeleteTag removes a tag.
+func (r *Repository) DeleteTag(name string) error {
+	return tagDeleteCmd(r.op(), name)
+}
+
+// Diff compares the working tree with the index.
+func (r *Repository) Diff() ([]FileDiff, error) {
+	return diffCmd(r.op(), nil)
+}
+
+// DiffStaged compares the index with HEAD.
+func (r *Repository) DiffStaged() ([]FileDiff, error) {
+	return diffCmd(r.op(), []string{"--staged"})
+}
+
+// DiffCommits compares two revisions.
+func (r *Repository) DiffCommits(a, b string) ([]FileDiff, error) {
+	return diffCmd(r.op(), []string{a, b})
+}
+
+// MergeBases returns a best common ancestor of a and b, or with all set
+// every one of them.
+func (r *Repository) MergeBases(a, b string, all bool) ([]string, error) {
+	return mergeBaseCmd(r.op(), a, b, all)
+}
+
+// IsAncestor reports whether a is an ancestor of (or the same as) b.
+func (r *Repository) IsAncestor(a, b string) (bool, error) {
+	return isAncestorCmd(r.op(), a, b)
+}
+
+// RevParse resolves a revision to its commit id, or a range to the ids it
+// includes and excludes.
+func (r *Repository) RevParse(spec string) (include, exclude []string, err error) {
+	return revParseCmd(r.op(), spec)
+}
+
+// Reflog returns the log of a branch (HEAD when empty), newest first.
+func (r *Repository) Reflog(name string) ([]ReflogEntry, error) {
+	return reflogCmd(r.op(), name)
+}
+
+// Abbreviate returns a function shortening commit ids to their shortest
+// unique prefix (never below core.minAbbrev), as of now.
+func (r *Repository) Abbreviate() (func(id string) string, error) {
+	rp := r.op()
+	if _, err := rp.open(); err != nil {
+		return nil, err
+	}
+	ab, err := newAbbreviator(rp)
+	if err != nil {
+		return nil, err
+	}
+	return ab.abbrev, nil
+}
+
+// GC removes unreachable objects older than the grace period (a Go
+// duration; gc.gracePeriod or two weeks when empty). With dryRun it only
+// reports them.
+func (r *Repository) GC(dryRun bool, grace string) (*GCResult, error) {
+	return gcCmd(r.op(), dryRun, grace)
+}
+
+// Fsck checks every object, link and ref; dangling objects are reported but
+// are not damage.
+func (r *Repository) Fsck() ([]FsckProblem, error) {
+	return fsckCmd(r.op())
+}
+
+// Repack moves every object into a single pack and returns how many.
+func (r *Repository) Repack() (int, error) {
+	return repackCmd(r.op())
+}
+
+// Migrate upgrades the repository to the current format, after a backup.
+func (r *Repository) Migrate() (*MigrateResult, error) {
+	return migrateCmd(r.op())
+}
+
+// Recover rolls back the checkout, reset or merge an interrupted process
+// left half-done, or with forward completes it, and returns its description.
+func (r *Repository) Recover(forward bool) (string, error) {
+	return recoverCmd(r.op(), forward)
+}
+
@@ -0,0 +1,93 @@
+package gitlet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// A repack through one handle must not leave another handle reading the
+// packs it deleted.
+func TestRepositoriesShareADirectory(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(r *Repository, name, data string) {
+		t.Helper()
+		writeFile(t, dir, name, data)
+		if err := r.Add(name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Commit("add " + name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := func(r *Repository) int {
+		t.Helper()
+		n := 0
+		for _, err := range r.Log("") {
+			if err != nil {
+				t.Fatal(err)
+			}
+			n++
+		}
+		return n
+	}
+
+	commit(a, "a.txt", strings.Repeat("a\n", 100))
+	if _, err := a.Repack(); err != nil {
+		t.Fatal(err)
+	}
+	if got := history(b); got != 2 {
+		t.Fatalf("b sees %d commits, want 2", got)
+	}
+	commit(b, "b.txt", strings.Repeat("b\n", 100))
+	if _, err := b.Repack(); err != nil {
+		t.Fatal(err)
+	}
+	if got := history(a); got != 3 {
+		t.Fatalf("a sees %d commits, want 3", got)
+	}
+	removeFile(t, dir, "a.txt")
+	if err := a.CheckoutFile("", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// An object store belongs to the handle it was given to, even when Init
+// fails.
+func TestWithObjectStoreStaysWithItsRepository(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := Init(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Init(dir, WithObjectStore(NewMemoryStore())); !errors.Is(err, ErrRepoExists) {
+		t.Fatalf("second Init: %v, want ErrRepoExists", err)
+	}
+	if _, err := disk.ReadCommit("HEAD"); err != nil {
+		t.Fatalf("disk handle after failed Init: %v", err)
+	}
+
+	mem := t.TempDir()
+	r, err := Init(mem, WithObjectStore(NewMemoryStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadCommit("HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	other, err := Open(mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ReadCommit("HEAD"); err == nil {
+		t.Fatal("a handle opened without the store read its objects")
+	}
+}
@@ -0,0 +1,47 @@
+package gitlet
+
+// resetCmd: reset <commit-id/prefix>
+func resetCmd(rp *repo, prefix string) (*CheckoutResult, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	unlock, err := lockRepo(root)
+	if err != nil { return nil, err }
+	defer unlock()
+
+	// Resolve target commit ID (abbreviated ids, branches, tags, HEAD~n, ...)
+	cid, err := resolveRevision(rp, prefix)
+	if err != nil { return nil, err } // No commit with that id exists.
+
+	return resetTo(rp, cid, "reset: moving to "+prefix)
+}
+
+// resetTo moves HEAD (and the working tree and index) to commit cid, logging
+// reason; merge uses it to fast-forward.
+func resetTo(rp *repo, cid, reason string) (*CheckoutResult, error) {
+
+	// Load target and current commits
+	target, err := readCommit(rp, cid)
+	if err != nil { return nil, err }
+
+	branch, curID, err := readHead(rp.root)
+	if err != nil { return nil, err }
+	current, err := readCommit(rp, curID)
+	if err != nil { return nil, err }
+
+	// Rewrite the working tree (with untracked-file protection), clear the
+	// index, and move the current branch ref to target commit (HEAD stays
+	// pointing to this ref), or HEAD itself when detached
+	j, err := beginJournal(rp, reason)
+	if err != nil { return nil, err }
+	if err := planSwitch(rp, j, current, target); err != nil { return nil, err }
+	j.moveHead(curID, cid)
+	// a reset abandons any merge in progress
+	j.Merge[afterSide] = nil
+	if err := j.run(rp); err != nil { return nil, err }
+
+	res := &CheckoutResult{Commit: cid, Branch: branch}
+	if branch == "" && cid != curID {
+		if res.Orphaned, err = orphanedCommits(rp, curID); err != nil { return nil, err }
+	}
+	return res, nil
+}
@@ -0,0 +1,139 @@
+package gitlet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultMinAbbrev is the shortest id prefix accepted (and displayed) unless
+// core.minAbbrev in the config says otherwise.
+const defaultMinAbbrev = 4
+
+// AmbiguousIDError reports an abbreviated id that matches several commits.
+type AmbiguousIDError struct {
+	Prefix     string
+	Candidates []string // full ids, sorted
+	Messages   []string // first message line of each candidate
+}
+
+func (e *AmbiguousIDError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Commit id %s is ambiguous. Candidates:", e.Prefix)
+	for i, id := range e.Candidates {
+		fmt.Fprintf(&sb, "\n  %s %s", id, e.Messages[i])
+	}
+	return sb.String()
+}
+
+// minAbbrev reads core.minAbbrev, falling back to defaultMinAbbrev.
+func minAbbrev(root string) int {
+	n, err := strconv.Atoi(loadConfig(root).get("core.minabbrev", ""))
+	if err != nil || n < 1 || n > 40 {
+		return defaultMinAbbrev
+	}
+	return n
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveCommitID returns a full 40-hex id for a tag name, or for a given
+// prefix (or exact id). Tag names win over id prefixes.
+// A miss returns "No commit with that id exists."; a prefix shared by several
+// commits returns an *AmbiguousIDError listing them.
+func resolveCommitID(rp *repo, prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if id, err := resolveTag(rp, prefix); err == nil {
+		return id, nil
+	}
+	if prefix == "" || len(prefix) > 40 || !isHex(prefix) {
+		return "", ErrNoCommit
+	}
+	if len(prefix) == 40 {
+		// verify it exists on disk
+		if hasObject(rp, "commits", prefix) {
+			return prefix, nil
+		}
+		return "", ErrNoCommit
+	}
+	if n := minAbbrev(rp.root); len(prefix) < n {
+		return "", restate(ErrBadRevision, fmt.Sprintf("Abbreviated commit ids must be at least %d characters.", n))
+	}
+
+	matches, err := commitIDsWithPrefix(rp, prefix)
+	if err != nil {
+		return "", err
+	}
+	switch len(matches) {
+	case 0:
+		return "", ErrNoCommit
+	case 1:
+		return matches[0], nil
+	}
+	amb := &AmbiguousIDError{Prefix: prefix, Candidates: matches}
+	for _, id := range matches {
+		msg := ""
+		if c, err := readCommitHeader(rp, id); err == nil {
+			msg = firstLine(c.Message)
+		}
+		amb.Messages = append(amb.Messages, msg)
+	}
+	return "", amb
+}
+
+// commitIDsWithPrefix returns, sorted, every stored commit id starting with
+// prefix.
+func commitIDsWithPrefix(rp *repo, prefix string) ([]string, error) {
+	return rp.store.WithPrefix("commits", prefix)
+}
+
+// listCommitIDs returns every stored commit id, sorted.
+func listCommitIDs(rp *repo) ([]string, error) {
+	return commitIDsWithPrefix(rp, "")
+}
+
+// abbreviator shortens commit ids to their shortest unique prefix (but never
+// below core.minAbbrev), using one snapshot of all ids for many lookups.
+type abbreviator struct {
+	ids []string
+	min int
+}
+
+func newAbbreviator(rp *repo) (*abbreviator, error) {
+	ids, err := listCommitIDs(rp)
+	if err != nil {
+		return nil, err
+	}
+	return &abbreviator{ids: ids, min: minAbbrev(rp.root)}, nil
+}
+
+func (a *abbreviator) abbrev(id string) string {
+	need := a.min
+	// only the sorted neighbours can share a longer prefix with id
+	i := sort.SearchStrings(a.ids, id)
+	for _, j := range []int{i - 1, i, i + 1} {
+		if j < 0 || j >= len(a.ids) || a.ids[j] == id {
+			continue
+		}
+		n := 0
+		for n < len(id) && n < len(a.ids[j]) && id[n] == a.ids[j][n] {
+			n++
+		}
+		if n+1 > need {
+			need = n + 1
+		}
+	}
+	if need > len(id) {
+		need = len(id)
+	}
+	return id[:need]
+}
@@ -0,0 +1,239 @@
+package gitlet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolveRevision turns a revision expression into a full commit id:
+//
+//	HEAD, @             the current commit
+//	<branch>, <tag>     a branch tip or tagged commit (branches win)
+//	<hex prefix>        an abbreviated or full commit id
+//	<ref>@{n}, @{n}     the value <ref> (default: current branch) had n updates ago
+//	<rev>~n             the n-th first-parent ancestor (~ alone means ~1)
+//	<rev>^n             the n-th parent: ^1 = Parent, ^2 = SecondParent, ^0 = itself
+//
+// Suffixes chain left to right, e.g. "master~2^2~1".
+func resolveRevision(rp *repo, spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.Contains(spec, "..") {
+		return "", restate(ErrBadRevision, fmt.Sprintf("Expected a single revision, got the range %s.", spec))
+	}
+	base, ops := splitRevision(spec)
+	id, err := resolveRevisionBase(rp, base)
+	if err != nil {
+		return "", err
+	}
+	for len(ops) > 0 {
+		op := ops[0]
+		j := 1
+		for j < len(ops) && ops[j] >= '0' && ops[j] <= '9' {
+			j++
+		}
+		n := 1
+		if j > 1 {
+			if n, err = strconv.Atoi(ops[1:j]); err != nil {
+				return "", ErrNoCommit
+			}
+		}
+		ops = ops[j:]
+		switch op {
+		case '~':
+			for ; n > 0; n-- {
+				if id, err = nthParent(rp, id, 1); err != nil {
+					return "", err
+				}
+			}
+		case '^':
+			if id, err = nthParent(rp, id, n); err != nil {
+				return "", err
+			}
+		default:
+			return "", ErrNoCommit
+		}
+	}
+	return id, nil
+}
+
+// splitRevision separates the name part of a revision from its ~/^ suffixes,
+// skipping over any @{...} selector.
+func splitRevision(spec string) (base, ops string) {
+	for i := 0; i < len(spec); i++ {
+		switch spec[i] {
+		case '{':
+			if end := strings.IndexByte(spec[i:], '}'); end >= 0 {
+				i += end
+			}
+		case '~', '^':
+			return spec[:i], spec[i:]
+		}
+	}
+	return spec, ""
+}
+
+func resolveRevisionBase(rp *repo, name string) (string, error) {
+	if name == "HEAD" || name == "@" {
+		return headCommitID(rp.root)
+	}
+	if i := strings.Index(name, "@{"); i >= 0 && strings.HasSuffix(name, "}") {
+		n, err := strconv.Atoi(name[i+2 : len(name)-1])
+		if err != nil || n < 0 {
+			return "", restate(ErrBadRevision, fmt.Sprintf("Invalid reflog selector in %s.", name))
+		}
+		return resolveReflog(rp.root, name[:i], n)
+	}
+	if name != "" {
+		if id, err := readBranchID(rp.root, name); err == nil {
+			return id, nil
+		}
+	}
+	// tag names, then id prefixes
+	return resolveCommitID(rp, name)
+}
+
+// nthParent returns parent n (1 or 2) of commit id; n == 0 is id itself.
+func nthParent(rp *repo, id string, n int) (string, error) {
+	if n == 0 {
+		return id, nil
+	}
+	c, err := readCommitHeader(rp, id)
+	if err != nil {
+		return "", err
+	}
+	ps := commitParents(c)
+	if n > len(ps) {
+		return "", ErrNoCommit
+	}
+	return ps[n-1], nil
+}
+
+// resolveReflog resolves ref@{n}, the value ref had n updates ago; an empty
+// ref means the current branch (HEAD when detached). A ref that was never
+// logged still has its current value as @{0}.
+func resolveReflog(root, ref string, n int) (string, error) {
+	if ref == "" {
+		b, err := currentBranch(root)
+		if err != nil {
+			return "", err
+		}
+		ref = b
+		if ref == "" {
+			ref = "HEAD"
+		}
+	}
+	logRef, err := reflogRef(root, ref)
+	if err != nil {
+		return "", ErrNoCommit
+	}
+	es, err := readReflog(root, logRef)
+	if err != nil {
+		return "", err
+	}
+	if len(es) == 0 && n == 0 {
+		if id := readRef(root, logRef); id != "" {
+			return id, nil
+		}
+	}
+	if n >= len(es) {
+		return "", restate(ErrBadRevision, fmt.Sprintf("Log for %s only has %d entries.", ref, len(es)))
+	}
+	return es[len(es)-1-n].New, nil
+}
+
+// resolveRange expands a revision or range into commits to include and to
+// exclude, as rev-parse prints them:
+//
+//	a..b    include b, exclude a (an empty side means HEAD)
+//	a...b   include a and b, exclude their merge bases
+//	rev     include rev
+func resolveRange(rp *repo, spec string) (include, exclude []string, err error) {
+	if l, r, ok := strings.Cut(spec, "..."); ok {
+		a, b, err := resolveRangeEnds(rp, l, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		bases, err := mergeBases(rp, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []string{a, b}, bases, nil
+	}
+	if l, r, ok := strings.Cut(spec, ".."); ok {
+		a, b, err := resolveRangeEnds(rp, l, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []string{b}, []string{a}, nil
+	}
+	id, err := resolveRevision(rp, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{id}, nil, nil
+}
+
+func resolveRangeEnds(rp *repo, l, r string) (string, string, error) {
+	if l == "" {
+		l = "HEAD"
+	}
+	if r == "" {
+		r = "HEAD"
+	}
+	a, err := resolveRevision(rp, l)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := resolveRevision(rp, r)
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+// rangeCommits lists the commits reachable from include but not from
+// exclude, newest first (ties broken by id).
+func rangeCommits(rp *repo, include, exclude []string) ([]string, error) {
+	in, err := ancestorsOf(rp, include)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ancestorsOf(rp, exclude)
+	if err != nil {
+		return nil, err
+	}
+	type entry struct{ id, ts string }
+	var es []entry
+	for id := range in {
+		if out[id] {
+			continue
+		}
+		c, err := readCommitHeader(rp, id)
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, entry{id, c.TimestampRFC})
+	}
+	sort.Slice(es, func(i, j int) bool {
+		ti, _ := parseCommitTime(es[i].ts)
+		tj, _ := parseCommitTime(es[j].ts)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return es[i].id < es[j].id
+	})
+	ids := make([]string, len(es))
+	for i, e := range es {
+		ids[i] = e.id
+	}
+	return ids, nil
+}
+
+// revParseCmd resolves spec to commit ids: one for a revision, or the
+// included and excluded ends of a range.
+func revParseCmd(rp *repo, spec string) (include, exclude []string, err error) {
+	if _, err := rp.open(); err != nil { return nil, nil, err }
+	return resolveRange(rp, spec)
+}
@@ -0,0 +1,54 @@
+package gitlet
+
+func rmCmd(rp *repo, filename string) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+
+	filename, err = normalizePath(filename)
+	if err != nil { return err }
+
+	// load index
+	idx, err := loadIndex(root)
+	if err != nil { return err }
+
+	// load HEAD commit
+	headID, err := headCommitID(root)
+	if err != nil { return err }
+	head, err := readCommit(rp, headID)
+	if err != nil { return err }
+
+	_, stagedAdd := idx.Adds[filename]
+	_, tracked := head.Files[filename]
+
+	// a conflicted file of an in-progress merge can be resolved by removal
+	// even when HEAD never had it
+	conflicted := false
+	if m, err := loadMergeState(root); err != nil {
+		return err
+	} else if m != nil {
+		for _, f := range m.Conflicts {
+			if f == filename { conflicted = true }
+		}
+	}
+
+	if !stagedAdd && !tracked && !conflicted {
+		return ErrNoReasonToRemove
+	}
+
+	// unstage addition if present
+	delete(idx.Adds, filename)
+
+	// if tracked: stage removal + delete from working dir if exists
+	if tracked {
+		idx.Removes[filename] = struct{}{}
+		removeWorkingFile(rp.dir, filename) // ignore if already gone
+	} else if conflicted {
+		removeWorkingFile(rp.dir, filename)
+	}
+
+	if err := idx.save(root); err != nil { return err }
+	return markResolved(root, filename)
+}
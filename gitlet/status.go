@@ -0,0 +1,93 @@
+package gitlet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Status holds the sorted contents of each `status` section.
+type Status struct {
+	Branches  []string
+	Current   string // "" when HEAD is detached
+	Detached  string // commit id HEAD is detached at
+	Staged    []string
+	Removed   []string
+	Modified  []string // "name (modified)" / "name (deleted)"
+	Untracked []string
+}
+
+func statusCmd(rp *repo) (*Status, error) {
+	if _, err := rp.open(); err != nil { return nil, err }
+	return computeStatus(rp)
+}
+
+func computeStatus(rp *repo) (*Status, error) {
+	st := &Status{}
+
+	// --- Branches ---
+	st.Branches = listBranches(rp.root)
+
+	curr, err := currentBranch(rp.root)
+	if err != nil { return nil, err }
+	st.Current = curr
+
+	// --- Staged / Removed Files ---
+	idx, _ := loadIndex(rp.root)
+	for f := range idx.Adds { st.Staged = append(st.Staged, f) }
+	sort.Strings(st.Staged)
+	for f := range idx.Removes { st.Removed = append(st.Removed, f) }
+	sort.Strings(st.Removed)
+
+	// --- Working tree vs HEAD and the index ---
+	headID, err := headCommitID(rp.root)
+	if err != nil { return nil, err }
+	if st.Current == "" { st.Detached = headID }
+	head, err := readCommit(rp, headID)
+	if err != nil { return nil, err }
+
+	files, err := listWorkingFiles(rp.dir)
+	if err != nil { return nil, err }
+	work := make(map[string]string, len(files)) // path -> blobID of working copy
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(rp.dir, filepath.FromSlash(f)))
+		if err != nil { continue }
+		work[f] = blobID(data)
+	}
+
+	modified := map[string]string{}
+	for f, staged := range idx.Adds {
+		// staged, then changed or deleted in the working tree
+		if w, ok := work[f]; !ok {
+			modified[f] = "deleted"
+		} else if w != staged {
+			modified[f] = "modified"
+		}
+	}
+	for f, tracked := range head.Files {
+		if _, staged := idx.Adds[f]; staged { continue }
+		if _, removed := idx.Removes[f]; removed { continue }
+		// tracked, unstaged, and changed or deleted in the working tree
+		if w, ok := work[f]; !ok {
+			modified[f] = "deleted"
+		} else if w != tracked {
+			modified[f] = "modified"
+		}
+	}
+	for f, kind := range modified {
+		st.Modified = append(st.Modified, fmt.Sprintf("%s (%s)", f, kind))
+	}
+	sort.Strings(st.Modified)
+
+	for _, f := range files {
+		if _, staged := idx.Adds[f]; staged { continue }
+		_, tracked := head.Files[f]
+		_, removed := idx.Removes[f]
+		// removed-then-recreated files count as untracked too
+		if !tracked || removed {
+			st.Untracked = append(st.Untracked, f)
+		}
+	}
+	return st, nil
+}
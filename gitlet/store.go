@@ -0,0 +1,227 @@
+package gitlet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ObjectStore holds a repository's immutable objects, addressed by kind
+// ("blobs", "commits", "trees", "tags") and id. Every object read and write
+// goes through the store of the repository (repo.store); refs, the index and
+// the rest of .gitlet stay plain files.
+type ObjectStore interface {
+	// Put stores data as object id; storing an object that is already
+	// there is a no-op.
+	Put(kind, id string, data []byte) error
+	// Get returns the content of object id; a missing object is an
+	// os.ErrNotExist error.
+	Get(kind, id string) ([]byte, error)
+	Has(kind, id string) bool
+	// Iterate calls fn with every id of kind, sorted, stopping at the first
+	// error fn returns.
+	Iterate(kind string, fn func(id string) error) error
+	// WithPrefix returns, sorted, every id of kind starting with prefix.
+	WithPrefix(kind, prefix string) ([]string, error)
+}
+
+// fsStore is the on-disk store: loose objects sharded by the first two hex
+// digits of their id under objects/<kind>/ (compressed, see encodeObject),
+// plus the packs under objects/pack/. Loose objects win over packed ones.
+// It is safe for concurrent use.
+type fsStore struct {
+	root string
+
+	mu    sync.Mutex
+	packs *packSet // see loadPacks
+}
+
+func objectPath(root, kind, id string) (string, string) {
+	// shard: ab/cdef...
+	subdir := filepath.Join(root, "objects", kind, id[:2])
+	return subdir, filepath.Join(subdir, id[2:])
+}
+
+func (s *fsStore) Put(kind, id string, data []byte) error {
+	if s.Has(kind, id) {
+		return nil // already there, loose or packed
+	}
+	_, path := objectPath(s.root, kind, id)
+	enc, err := encodeObject(kind, data)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, enc)
+}
+
+func (s *fsStore) Get(kind, id string) ([]byte, error) {
+	b, _, err := s.load(kind, id)
+	return b, err
+}
+
+// load is Get that also returns the file the object came from.
+func (s *fsStore) load(kind, id string) ([]byte, string, error) {
+	if len(id) < 2 {
+		return nil, "", fmt.Errorf("bad object id %q", id)
+	}
+	_, path := objectPath(s.root, kind, id)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		data, packPath, ok, perr := s.findPacked(kind, id)
+		if ok || perr != nil {
+			if perr != nil {
+				return nil, packPath, fmt.Errorf("%s %s: %w", objectTypeTag[kind], id, perr)
+			}
+			return data, packPath, nil
+		}
+	}
+	if err != nil {
+		return nil, path, err
+	}
+	b, err := decodeObject(kind, id, raw)
+	if err != nil {
+		return nil, path, fmt.Errorf("%s %s: %v", objectTypeTag[kind], id, err)
+	}
+	return b, path, nil
+}
+
+func (s *fsStore) Has(kind, id string) bool {
+	if len(id) < 2 {
+		return false
+	}
+	if _, path := objectPath(s.root, kind, id); fileExists(path) {
+		return true
+	}
+	ps, _ := s.loadPacks()
+	for _, p := range ps {
+		if i, ok := p.find(id); ok && p.kind(i) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fsStore) Iterate(kind string, fn func(id string) error) error {
+	ids, err := s.WithPrefix(kind, "")
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fsStore) WithPrefix(kind, prefix string) ([]string, error) {
+	dir := filepath.Join(s.root, "objects", kind)
+	var shards []string
+
+	// If we have >=2 hex, we only need to search that shard.
+	if len(prefix) >= 2 {
+		shards = []string{prefix[:2]}
+	} else {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+				shards = append(shards, e.Name())
+			}
+		}
+	}
+
+	matches, err := s.packedIDs(kind, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, sh := range shards {
+		entries, err := os.ReadDir(filepath.Join(dir, sh))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, e := range entries {
+			id := sh + e.Name() // full id = shard + rest
+			if !e.IsDir() && strings.HasPrefix(id, prefix) && !strings.HasPrefix(e.Name(), ".tmp-") {
+				matches = append(matches, id)
+			}
+		}
+	}
+	// an object can be both packed and loose
+	sort.Strings(matches)
+	return slices.Compact(matches), nil
+}
+
+// where names the file object id is read from, for error messages.
+func (s *fsStore) where(kind, id string) string {
+	_, path, _ := s.load(kind, id)
+	return path
+}
+
+// memStore keeps objects in memory only, for embedding gitlet where the
+// object database should not touch the disk (services, tests). It is safe
+// for concurrent use.
+type memStore struct {
+	mu   sync.RWMutex
+	objs map[string]map[string][]byte // kind -> id -> content
+}
+
+func newMemStore() *memStore {
+	return &memStore{objs: map[string]map[string][]byte{}}
+}
+
+func (m *memStore) Put(kind, id string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.objs[kind] == nil {
+		m.objs[kind] = map[string][]byte{}
+	}
+	if _, ok := m.objs[kind][id]; !ok {
+		m.objs[kind][id] = slices.Clone(data)
+	}
+	return nil
+}
+
+func (m *memStore) Get(kind, id string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	b, ok := m.objs[kind][id]
+	if !ok {
+		return nil, fmt.Errorf("%s %s: %w", objectTypeTag[kind], id, os.ErrNotExist)
+	}
+	return slices.Clone(b), nil
+}
+
+func (m *memStore) Has(kind, id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.objs[kind][id]
+	return ok
+}
+
+func (m *memStore) Iterate(kind string, fn func(id string) error) error {
+	ids, _ := m.WithPrefix(kind, "")
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memStore) WithPrefix(kind, prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var ids []string
+	for id := range m.objs[kind] {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
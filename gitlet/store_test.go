@@ -0,0 +1,145 @@
+package gitlet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestObjectStoreContract(t *testing.T) {
+	fsRepo := func(t *testing.T) *repo {
+		root := filepath.Join(t.TempDir(), ".gitlet")
+		return &repo{root: root, store: &fsStore{root: root}}
+	}
+	tests := []struct {
+		name string
+		// open returns an empty store and a function called once the
+		// contract's objects are stored, before they are read back
+		open func(t *testing.T) (ObjectStore, func())
+	}{
+		{"memory", func(t *testing.T) (ObjectStore, func()) {
+			return newMemStore(), func() {}
+		}},
+		{"loose files", func(t *testing.T) (ObjectStore, func()) {
+			rp := fsRepo(t)
+			return rp.store, func() {}
+		}},
+		{"packed files", func(t *testing.T) (ObjectStore, func()) {
+			rp := fsRepo(t)
+			return rp.store, func() {
+				if _, err := repack(rp, nil, true); err != nil {
+					t.Fatal(err)
+				}
+				if loose, _ := listObjects(rp.root, "blobs"); len(loose) > 0 {
+					t.Fatalf("%d blobs still loose after repack", len(loose))
+				}
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, settle := tt.open(t)
+			testObjectStore(t, s, settle)
+		})
+	}
+}
+
+// testObjectStore checks the ObjectStore contract on an empty store s.
+func testObjectStore(t *testing.T, s ObjectStore, settle func()) {
+	contents := [][]byte{[]byte("one\n"), []byte("two\n"), []byte("three\n"), {}}
+	var ids []string
+	for _, c := range contents {
+		id := hashObject("blobs", c)
+		ids = append(ids, id)
+		data := slices.Clone(c)
+		if err := s.Put("blobs", id, data); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Put("blobs", id, data); err != nil {
+			t.Fatalf("storing %s again: %v", id, err)
+		}
+		if len(data) > 0 {
+			data[0] = 'X' // the store must not keep the caller's slice
+		}
+	}
+	commit := []byte("not a blob\n")
+	commitID := hashObject("commits", commit)
+	if err := s.Put("commits", commitID, commit); err != nil {
+		t.Fatal(err)
+	}
+	settle()
+
+	for i, id := range ids {
+		if !s.Has("blobs", id) {
+			t.Errorf("Has(blobs, %s) = false", id)
+		}
+		got, err := s.Get("blobs", id)
+		if err != nil {
+			t.Fatalf("Get(blobs, %s): %v", id, err)
+		}
+		if !bytes.Equal(got, contents[i]) {
+			t.Fatalf("Get(blobs, %s) = %q, want %q", id, got, contents[i])
+		}
+		if len(got) > 0 {
+			got[0] = 'X' // nor hand out its own
+		}
+		if again, _ := s.Get("blobs", id); !bytes.Equal(again, contents[i]) {
+			t.Fatalf("Get(blobs, %s) changed to %q", id, again)
+		}
+	}
+
+	missing := hashObject("blobs", []byte("missing\n"))
+	if s.Has("blobs", missing) {
+		t.Error("Has reports a missing object")
+	}
+	if _, err := s.Get("blobs", missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get of a missing object: %v, want os.ErrNotExist", err)
+	}
+	if s.Has("blobs", commitID) {
+		t.Error("Has finds a commit among the blobs")
+	}
+	if _, err := s.Get("trees", ids[0]); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get of a blob as a tree: %v, want os.ErrNotExist", err)
+	}
+
+	sorted := slices.Sorted(slices.Values(ids))
+	var seen []string
+	err := s.Iterate("blobs", func(id string) error {
+		seen = append(seen, id)
+		return nil
+	})
+	if err != nil || !slices.Equal(seen, sorted) {
+		t.Fatalf("Iterate(blobs) = %v, %v; want %v", seen, err, sorted)
+	}
+	stop := errors.New("stop")
+	n := 0
+	err = s.Iterate("blobs", func(string) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Fatalf("Iterate after an error: %d calls, %v", n, err)
+	}
+
+	for _, prefix := range []string{"", sorted[1][:1], sorted[1][:2], sorted[1][:5], sorted[1], "zz"} {
+		var want []string
+		for _, id := range sorted {
+			if len(id) >= len(prefix) && id[:len(prefix)] == prefix {
+				want = append(want, id)
+			}
+		}
+		got, err := s.WithPrefix("blobs", prefix)
+		if err != nil || !slices.Equal(got, want) {
+			t.Fatalf("WithPrefix(blobs, %q) = %v, %v; want %v", prefix, got, err, want)
+		}
+	}
+	if got, err := s.WithPrefix("commits", ""); err != nil || !slices.Equal(got, []string{commitID}) {
+		t.Fatalf("WithPrefix(commits) = %v, %v", got, err)
+	}
+	if got, err := s.WithPrefix("tags", ""); err != nil || len(got) != 0 {
+		t.Fatalf("WithPrefix(tags) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,206 @@
+package gitlet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tag is an annotated tag object, stored under objects/tags. Lightweight tags
+// have no object: their ref file holds the commit id directly.
+type Tag struct {
+	Object       string // tagged commit id
+	Name         string
+	Tagger       string // "Name <email>"
+	TimestampRFC string // same format as Commit.TimestampRFC
+	Message      string // may span several lines; always serialized last
+}
+
+// CanonicalBytes uses the commit layout (key\nvalue\n) with the message last,
+// so it can hold newlines.
+func (t *Tag) CanonicalBytes() []byte {
+	var b []byte
+	appendKV := func(k, v string) {
+		b = append(b, k...)
+		b = append(b, '\n')
+		b = append(b, v...)
+		b = append(b, '\n')
+	}
+	appendKV("object", t.Object)
+	appendKV("tag", t.Name)
+	appendKV("tagger", t.Tagger)
+	appendKV("timestamp", t.TimestampRFC)
+	appendKV("message", t.Message)
+	return b
+}
+
+func (t *Tag) ID() string {
+	h := sha1.New()
+	h.Write([]byte("tag\n"))
+	h.Write(t.CanonicalBytes())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func readTag(rp *repo, id string) (*Tag, error) {
+	b, err := readObject(rp, "tags", id)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tag{}
+	rest := string(b)
+	for _, key := range []string{"object", "tag", "tagger", "timestamp", "message"} {
+		k, after, ok := strings.Cut(rest, "\n")
+		if !ok || k != key {
+			return nil, fmt.Errorf("bad tag %s: expected %q", id, key)
+		}
+		if key == "message" {
+			t.Message = strings.TrimSuffix(after, "\n")
+			break
+		}
+		v, after, _ := strings.Cut(after, "\n")
+		rest = after
+		switch key {
+		case "object":
+			t.Object = v
+		case "tag":
+			t.Name = v
+		case "tagger":
+			t.Tagger = v
+		case "timestamp":
+			t.TimestampRFC = v
+		}
+	}
+	return t, nil
+}
+
+func tagRefPath(root, name string) string {
+	return filepath.Join(root, "refs", "tags", filepath.FromSlash(name))
+}
+
+// validTagName rejects names that could escape refs/tags or be mistaken for
+// revision syntax (~, ^, @{, ..).
+func validTagName(name string) bool {
+	return name != "" && name != "HEAD" && name != "@" && !strings.HasPrefix(name, "-") &&
+		!strings.HasPrefix(name, "/") && !strings.HasSuffix(name, "/") &&
+		!strings.Contains(name, "..") && !strings.Contains(name, "@{") &&
+		!strings.ContainsAny(name, " \t\n\\~^:")
+}
+
+// resolveTag returns the commit a tag names, peeling annotated tags.
+func resolveTag(rp *repo, name string) (string, error) {
+	if !validTagName(name) {
+		return "", ErrNoSuchTag
+	}
+	b, err := os.ReadFile(tagRefPath(rp.root, name))
+	if err != nil {
+		return "", ErrNoSuchTag
+	}
+	id := strings.TrimSpace(string(b))
+	if len(id) < 2 {
+		return "", ErrNoSuchTag
+	}
+	if hasObject(rp, "tags", id) {
+		t, err := readTag(rp, id)
+		if err != nil {
+			return "", err
+		}
+		return t.Object, nil
+	}
+	return id, nil
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// listTags returns all tag names (including ones with slashes), sorted.
+func listTags(root string) ([]string, error) {
+	base := filepath.Join(root, "refs", "tags")
+	var names []string
+	err := filepath.WalkDir(base, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || isLockOrTemp(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// tagListCmd returns every tag name, sorted.
+func tagListCmd(rp *repo) ([]string, error) {
+	root, err := rp.open()
+	if err != nil { return nil, err }
+	return listTags(root)
+}
+
+// tagCmd creates a tag on target (HEAD when empty). With annotated set it
+// writes a tag object carrying the tagger, date and message.
+func tagCmd(rp *repo, name, target string, annotated bool, msg string) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+	if !validTagName(name) { return ErrBadTagName }
+	refPath := tagRefPath(root, name)
+	if fileExists(refPath) { return ErrTagExists }
+
+	var cid string
+	if target == "" {
+		cid, err = headCommitID(root)
+	} else {
+		cid, err = resolveRevision(rp, target)
+	}
+	if err != nil { return err }
+
+	refTarget := cid
+	if annotated {
+		if strings.TrimSpace(msg) == "" {
+			return ErrNoTagMessage
+		}
+		t := &Tag{
+			Object:       cid,
+			Name:         name,
+			Tagger:       resolveIdentity(loadConfig(root), "COMMITTER").String(),
+			TimestampRFC: commitTimestamp(time.Now()),
+			Message:      msg,
+		}
+		refTarget = t.ID()
+		if err := ensureObjectStored(rp, "tags", refTarget, t.CanonicalBytes()); err != nil {
+			return err
+		}
+	}
+	return updateRef(root, "refs/tags/"+name, zeroID, refTarget, "")
+}
+
+// tagDeleteCmd removes a tag ref; an annotated tag's object stays in the store.
+func tagDeleteCmd(rp *repo, name string) error {
+	root, err := rp.open()
+	if err != nil { return err }
+	unlock, err := lockRepo(root)
+	if err != nil { return err }
+	defer unlock()
+	if !validTagName(name) || !fileExists(tagRefPath(root, name)) {
+		return ErrNoSuchTag
+	}
+	return deleteRef(root, "refs/tags/"+name)
+}
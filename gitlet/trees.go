@@ -0,0 +1,203 @@
+package gitlet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A tree object stores one directory level: sorted "kind\tid\tname" lines where
+// kind is "blob" for a file or "tree" for a subdirectory. Commits point at the
+// root tree, so unchanged subdirectories share a single object across commits.
+type treeEntry struct {
+	Kind string // "blob" or "tree"
+	ID   string
+	Name string
+}
+
+// treeID = SHA1("tree\n" + canonical tree bytes)
+func treeID(data []byte) string {
+	h := sha1.New()
+	h.Write([]byte("tree\n"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func treeBytes(entries []treeEntry) []byte {
+	sorted := append([]treeEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Kind < sorted[j].Kind
+	})
+	var b []byte
+	for _, e := range sorted {
+		b = append(b, fmt.Sprintf("%s\t%s\t%s\n", e.Kind, e.ID, e.Name)...)
+	}
+	return b
+}
+
+// treeNode is the in-memory shape used while building trees from a flat snapshot.
+type treeNode struct {
+	blobs map[string]string
+	dirs  map[string]*treeNode
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{blobs: map[string]string{}, dirs: map[string]*treeNode{}}
+}
+
+// writeTree stores the tree objects for a flat path -> blobID snapshot and
+// returns the root tree id. Objects that already exist are not rewritten.
+func writeTree(rp *repo, files map[string]string) (string, error) {
+	top := newTreeNode()
+	for p, bid := range files {
+		parts := strings.Split(p, "/")
+		n := top
+		for _, d := range parts[:len(parts)-1] {
+			child, ok := n.dirs[d]
+			if !ok {
+				child = newTreeNode()
+				n.dirs[d] = child
+			}
+			n = child
+		}
+		n.blobs[parts[len(parts)-1]] = bid
+	}
+	return top.store(rp)
+}
+
+func (n *treeNode) store(rp *repo) (string, error) {
+	entries := make([]treeEntry, 0, len(n.blobs)+len(n.dirs))
+	for name, bid := range n.blobs {
+		entries = append(entries, treeEntry{Kind: "blob", ID: bid, Name: name})
+	}
+	for name, child := range n.dirs {
+		tid, err := child.store(rp)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, treeEntry{Kind: "tree", ID: tid, Name: name})
+	}
+	data := treeBytes(entries)
+	tid := treeID(data)
+	if err := ensureObjectStored(rp, "trees", tid, data); err != nil {
+		return "", err
+	}
+	return tid, nil
+}
+
+// readTree loads one tree level. The empty id stands for an empty tree.
+func readTree(rp *repo, id string) ([]treeEntry, error) {
+	if id == "" {
+		return nil, nil
+	}
+	b, err := readObject(rp, "trees", id)
+	if err != nil {
+		return nil, err
+	}
+	var entries []treeEntry
+	for _, line := range strings.Split(string(b), "\n") {
+		if line == "" {
+			continue
+		}
+		p := strings.SplitN(line, "\t", 3)
+		if len(p) != 3 {
+			return nil, fmt.Errorf("bad tree %s: %q", id, line)
+		}
+		entries = append(entries, treeEntry{Kind: p[0], ID: p[1], Name: p[2]})
+	}
+	return entries, nil
+}
+
+// flattenTree expands a root tree into the flat path -> blobID map commands work on.
+func flattenTree(rp *repo, id string) (map[string]string, error) {
+	files := map[string]string{}
+	var walk func(id, prefix string) error
+	walk = func(id, prefix string) error {
+		entries, err := readTree(rp, id)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			switch e.Kind {
+			case "blob":
+				files[prefix+e.Name] = e.ID
+			case "tree":
+				if err := walk(e.ID, prefix+e.Name+"/"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return files, walk(id, "")
+}
+
+// diffTrees reports every path whose blob differs between trees a and b as
+// path -> [blobInA, blobInB] ("" when absent). Subtrees with equal ids are
+// skipped without being read.
+func diffTrees(rp *repo, a, b string) (map[string][2]string, error) {
+	out := map[string][2]string{}
+	var walk func(a, b, prefix string) error
+	walk = func(a, b, prefix string) error {
+		if a == b {
+			return nil
+		}
+		ea, err := readTree(rp, a)
+		if err != nil {
+			return err
+		}
+		eb, err := readTree(rp, b)
+		if err != nil {
+			return err
+		}
+		type key struct{ kind, name string }
+		side := map[key][2]string{}
+		for _, e := range ea {
+			k := key{e.Kind, e.Name}
+			side[k] = [2]string{e.ID, side[k][1]}
+		}
+		for _, e := range eb {
+			k := key{e.Kind, e.Name}
+			side[k] = [2]string{side[k][0], e.ID}
+		}
+		for k, ids := range side {
+			if ids[0] == ids[1] {
+				continue
+			}
+			if k.kind == "tree" {
+				if err := walk(ids[0], ids[1], prefix+k.name+"/"); err != nil {
+					return err
+				}
+				continue
+			}
+			out[prefix+k.name] = ids
+		}
+		return nil
+	}
+	return out, walk(a, b, "")
+}
+
+// diffCommits is diffTrees for two commits, falling back to comparing the
+// flat maps when either commit predates tree objects.
+func diffCommits(rp *repo, a, b *Commit) (map[string][2]string, error) {
+	if a.Tree != "" && b.Tree != "" {
+		return diffTrees(rp, a.Tree, b.Tree)
+	}
+	out := map[string][2]string{}
+	for f, bid := range a.Files {
+		if b.Files[f] != bid {
+			out[f] = [2]string{bid, b.Files[f]}
+		}
+	}
+	for f, bid := range b.Files {
+		if _, ok := a.Files[f]; !ok {
+			out[f] = [2]string{"", bid}
+		}
+	}
+	return out, nil
+}